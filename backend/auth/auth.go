@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"BookingTimeSlot/backend/pkg"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Roles accepted in tokens
+const (
+	RoleAdmin       = "admin"
+	RoleInterviewer = "interviewer"
+)
+
+// Secret signs and verifies tokens. It must be set before tokens are issued or checked.
+var Secret []byte
+
+// Claims identify the caller of an authenticated request
+type Claims struct {
+	Subject        string `json:"sub"`
+	Role           string `json:"role"`
+	InterviewerID  uint   `json:"interviewer_id,omitempty"`
+	OrganizationID uint   `json:"org_id,omitempty"` // tenant the caller belongs to; 0 for platform admins
+	ExpiresAt      int64  `json:"exp"`
+}
+
+// IssueToken signs claims as "<payload>.<signature>", both base64url encoded
+func IssueToken(claims Claims) (string, error) {
+	if len(Secret) == 0 {
+		return "", errors.New("auth secret is not configured")
+	}
+	if claims.Role != RoleAdmin && claims.Role != RoleInterviewer {
+		return "", errors.New("role must be admin or interviewer")
+	}
+	if claims.Role == RoleInterviewer && claims.OrganizationID == 0 {
+		return "", errors.New("interviewer tokens must belong to an organization")
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + sign(encoded), nil
+}
+
+// ParseToken verifies a token's signature and expiry and returns its claims
+func ParseToken(token string) (*Claims, error) {
+	if len(Secret) == 0 {
+		return nil, errors.New("auth secret is not configured")
+	}
+
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(sign(encoded))) {
+		return nil, errors.New("invalid token")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("invalid token")
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errors.New("invalid token")
+	}
+	if claims.ExpiresAt != 0 && time.Now().Unix() >= claims.ExpiresAt {
+		return nil, errors.New("token has expired")
+	}
+	return &claims, nil
+}
+
+// Middleware reads an optional "Authorization: Bearer <token>" header and stores
+// the claims in the context. Requests with an invalid token are rejected.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			c.Error(pkg.Unauthorized("invalid_authorization_header", "Authorization header must be a bearer token"))
+			c.Abort()
+			return
+		}
+		claims, err := ParseToken(token)
+		if err != nil {
+			c.Error(pkg.Unauthorized("invalid_token", err.Error()))
+			c.Abort()
+			return
+		}
+
+		c.Set("claims", claims)
+		c.Next()
+	}
+}
+
+// RequireRole only lets requests through whose token carries one of the roles
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := ClaimsFrom(c)
+		if claims == nil {
+			c.Error(pkg.Unauthorized("authentication_required", "Authentication required"))
+			c.Abort()
+			return
+		}
+		for _, role := range roles {
+			if claims.Role == role {
+				c.Next()
+				return
+			}
+		}
+		c.Error(pkg.Forbidden("insufficient_permissions", "Insufficient permissions"))
+		c.Abort()
+	}
+}
+
+// ClaimsFrom returns the authenticated caller, or nil for anonymous requests
+func ClaimsFrom(c *gin.Context) *Claims {
+	if value, ok := c.Get("claims"); ok {
+		if claims, ok := value.(*Claims); ok {
+			return claims
+		}
+	}
+	return nil
+}
+
+// sign returns the base64url HMAC-SHA256 of the payload
+func sign(payload string) string {
+	mac := hmac.New(sha256.New, Secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"BookingTimeSlot/backend/handler"
+	"BookingTimeSlot/backend/pkg"
 	"log"
 	"net/http"
 	"time"
@@ -62,6 +64,21 @@ func CreateTimeSlot(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// Same rules as the main server's slots
+	if err := pkg.ValidateTimeSlot(timeSlot.InterviewerID, timeSlot.StartTime, timeSlot.EndTime); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var overlapping int64
+	if err := db.Model(&TimeSlot{}).Where("interviewer_id = ? AND start_time < ? AND end_time > ?", timeSlot.InterviewerID, timeSlot.EndTime, timeSlot.StartTime).Count(&overlapping).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if overlapping > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Time slot overlaps an existing slot of this interviewer"})
+		return
+	}
+	timeSlot.IsBooked = false
 	if err := db.Create(&timeSlot).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -159,12 +176,12 @@ func main() {
 	router := gin.Default()
 	router.Use(CORSMiddleware()) // Enable CORS
 
-	// Set up API routes
-	router.POST("/api/time_slots", CreateTimeSlot)
-	router.GET("/api/availability", GetAvailableTimeSlots) // Fixed the endpoint to be /api/availability
-	router.POST("/api/book_time_slot", BookTimeSlot)
-	router.GET("/api/events", GetEvents)
-	router.DELETE("/api/events/:id", DeleteEvent)
+	// Set up API routes; superseded by the main server's /api/v1
+	router.POST("/api/time_slots", handler.Deprecated("/api/v1/slots"), CreateTimeSlot)
+	router.GET("/api/availability", handler.Deprecated("/api/v1/slots"), GetAvailableTimeSlots) // Fixed the endpoint to be /api/availability
+	router.POST("/api/book_time_slot", handler.Deprecated("/api/v1/bookings"), BookTimeSlot)
+	router.GET("/api/events", handler.Deprecated("/api/v1/events"), GetEvents)
+	router.DELETE("/api/events/:id", handler.Deprecated("/api/v1/events/:id"), DeleteEvent)
 
 	// Start the server
 	router.Run(":8080")
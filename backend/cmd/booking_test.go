@@ -0,0 +1,337 @@
+package main
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/pkg"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCancelTimeSlotBooking(t *testing.T) {
+	s := newTestServer(t)
+	acme := s.organization("acme")
+	slot := futureSlot(t, acme, 1, 2)
+
+	var created struct{ Booking pkg.Booking }
+	s.expect(s.do(http.MethodPost, "/api/v1/bookings", acme, "", map[string]interface{}{
+		"slot_id": slot.ID, "name": "Ada", "email": "ada@example.com",
+	}), http.StatusOK, &created)
+
+	path := fmt.Sprintf("/api/v1/bookings/%d", created.Booking.ID)
+	s.expect(s.do(http.MethodDelete, path, acme, s.token(auth.RoleAdmin, acme, 0), nil), http.StatusOK, nil)
+
+	scoped := pkg.ForOrganization(db, acme.ID)
+	var booking pkg.Booking
+	if err := scoped.First(&booking, created.Booking.ID).Error; err != nil {
+		t.Fatalf("cancelled booking is gone: %v", err)
+	}
+	if booking.Status != "cancelled" {
+		t.Errorf("status = %q, want cancelled", booking.Status)
+	}
+	if err := scoped.First(slot, slot.ID).Error; err != nil || slot.IsBooked {
+		t.Errorf("slot still booked after cancellation (err %v)", err)
+	}
+	var events []pkg.Event
+	scoped.Where("booking_id = ?", booking.ID).Find(&events)
+	for _, event := range events {
+		if event.Status != pkg.EventCancelled {
+			t.Errorf("event %d is %s, want cancelled", event.ID, event.Status)
+		}
+	}
+
+	// Cancelling again changes nothing
+	s.expect(s.do(http.MethodDelete, path, acme, s.token(auth.RoleAdmin, acme, 0), nil), http.StatusOK, nil)
+}
+
+func TestCancelScheduleBookingFreesInterval(t *testing.T) {
+	s := newTestServer(t)
+	acme := s.organization("acme")
+	day := time.Now().UTC().AddDate(0, 0, 2)
+	if _, err := pkg.SaveScheduleTemplate(pkg.ForOrganization(db, acme.ID), 1, int(day.Weekday()), "09:00", "11:00", 60, "UTC"); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(day.Year(), day.Month(), day.Day(), 9, 0, 0, 0, time.UTC)
+	request := map[string]interface{}{
+		"interviewer_id": 1, "start_time": start, "end_time": start.Add(time.Hour), "name": "Ada", "email": "ada@example.com",
+	}
+
+	var created struct{ Booking pkg.Booking }
+	s.expect(s.do(http.MethodPost, "/api/v1/bookings", acme, "", request), http.StatusOK, &created)
+	s.expect(s.do(http.MethodPost, "/api/v1/bookings", acme, "", request), http.StatusConflict, nil)
+
+	path := fmt.Sprintf("/api/v1/bookings/%d", created.Booking.ID)
+	s.expect(s.do(http.MethodDelete, path, acme, s.token(auth.RoleAdmin, acme, 0), nil), http.StatusOK, nil)
+	s.expect(s.do(http.MethodPost, "/api/v1/bookings", acme, "", request), http.StatusOK, nil)
+}
+
+func TestActiveBookingLimitCountsEveryRoute(t *testing.T) {
+	s := newTestServer(t)
+	acme := s.organization("acme")
+	defer func(limit int) { pkg.MaxActiveBookingsPerEmail = limit }(pkg.MaxActiveBookingsPerEmail)
+	pkg.MaxActiveBookingsPerEmail = 2
+
+	// The legacy route stores start_time too, so its bookings count
+	day := time.Now().UTC().AddDate(0, 0, 2)
+	if _, err := pkg.SaveScheduleTemplate(pkg.ForOrganization(db, acme.ID), 1, int(day.Weekday()), "09:00", "12:00", 60, "UTC"); err != nil {
+		t.Fatal(err)
+	}
+	s.expect(s.do(http.MethodPost, "/api/book-slot", acme, "", map[string]interface{}{
+		"name": "Ada", "user_id": 1, "interviewer_id": 1, "email": "ada@example.com",
+		"slot_date": day.Format("2006-01-02"), "time_slot": "10:00 AM",
+	}), http.StatusOK, nil)
+	s.expect(s.do(http.MethodPost, "/api/v1/bookings", acme, "", map[string]interface{}{
+		"slot_id": futureSlot(t, acme, 2, 3).ID, "name": "Ada", "email": "Ada@Example.com",
+	}), http.StatusOK, nil)
+
+	var problem struct{ Code string }
+	s.expect(s.do(http.MethodPost, "/api/v1/bookings", acme, "", map[string]interface{}{
+		"slot_id": futureSlot(t, acme, 2, 4).ID, "name": "Ada", "email": "ada@example.com",
+	}), http.StatusConflict, &problem)
+	if problem.Code != "active_booking_limit" {
+		t.Errorf("code = %q, want active_booking_limit", problem.Code)
+	}
+}
+
+func TestEveryBookingRouteWaitsForEmailVerification(t *testing.T) {
+	s := newTestServer(t)
+	acme := s.organization("acme")
+	defer func(verify bool) { pkg.VerifyBookingEmails = verify }(pkg.VerifyBookingEmails)
+	pkg.VerifyBookingEmails = true
+
+	day := time.Now().UTC().AddDate(0, 0, 2)
+	for _, interviewerID := range []uint{2, 3} {
+		if _, err := pkg.SaveScheduleTemplate(pkg.ForOrganization(db, acme.ID), interviewerID, int(day.Weekday()), "09:00", "12:00", 60, "UTC"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Date(day.Year(), day.Month(), day.Day(), 9, 0, 0, 0, time.UTC)
+
+	var timeSlot, schedule struct{ Booking pkg.Booking }
+	s.expect(s.do(http.MethodPost, "/api/v1/bookings", acme, "", map[string]interface{}{
+		"slot_id": futureSlot(t, acme, 1, 2).ID, "name": "Ada", "email": "ada@example.com",
+	}), http.StatusOK, &timeSlot)
+	s.expect(s.do(http.MethodPost, "/api/v1/bookings", acme, "", map[string]interface{}{
+		"interviewer_id": 2, "start_time": start, "end_time": start.Add(time.Hour), "name": "Bea", "email": "bea@example.com",
+	}), http.StatusOK, &schedule)
+	var legacy struct {
+		BookingID uint `json:"booking_id"`
+		Status    string
+	}
+	s.expect(s.do(http.MethodPost, "/api/book-slot", acme, "", map[string]interface{}{
+		"name": "Cy", "user_id": 1, "interviewer_id": 3, "email": "cy@example.com",
+		"slot_date": day.Format("2006-01-02"), "time_slot": "11:00 AM",
+	}), http.StatusOK, &legacy)
+	if legacy.Status != pkg.BookingPendingVerification {
+		t.Errorf("legacy response status = %q, want %s", legacy.Status, pkg.BookingPendingVerification)
+	}
+
+	for _, id := range []uint{timeSlot.Booking.ID, schedule.Booking.ID, legacy.BookingID} {
+		var booking pkg.Booking
+		if err := pkg.ForOrganization(db, acme.ID).First(&booking, id).Error; err != nil {
+			t.Fatal(err)
+		}
+		if booking.Status != pkg.BookingPendingVerification || booking.VerifyToken == nil || booking.ManageToken != nil {
+			t.Errorf("booking %d: status %q, verification link sent %t, manage link sent %t; want only a pending verification",
+				id, booking.Status, booking.VerifyToken != nil, booking.ManageToken != nil)
+		}
+	}
+
+	// The legacy route cannot verify a booking without an email address
+	s.expect(s.do(http.MethodPost, "/api/book-slot", acme, "", map[string]interface{}{
+		"name": "Di", "user_id": 2, "interviewer_id": 4,
+		"slot_date": day.Format("2006-01-02"), "time_slot": "11:00 AM",
+	}), http.StatusBadRequest, nil)
+}
+
+func TestLegacyBookingFollowsTheSchedule(t *testing.T) {
+	s := newTestServer(t)
+	acme := s.organization("acme")
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	day := time.Now().In(location).AddDate(0, 0, 2)
+	date := day.Format("2006-01-02")
+	if _, err := pkg.SaveScheduleTemplate(pkg.ForOrganization(db, acme.ID), 1, int(day.Weekday()), "09:00", "12:00", 30, location.String()); err != nil {
+		t.Fatal(err)
+	}
+	var problem struct{ Code string }
+	book := func(timeSlot string, status int, code string) {
+		t.Helper()
+		s.expect(s.do(http.MethodPost, "/api/book-slot", acme, "", map[string]interface{}{
+			"name": "Bea", "user_id": 2, "interviewer_id": 1, "email": "bea@example.com",
+			"slot_date": date, "time_slot": timeSlot,
+		}), status, &problem)
+		if problem.Code != code {
+			t.Errorf("%s: code = %q, want %s", timeSlot, problem.Code, code)
+		}
+	}
+
+	// The time is the interviewer's wall clock and the end follows the schedule's step
+	var created struct {
+		BookingID uint `json:"booking_id"`
+	}
+	s.expect(s.do(http.MethodPost, "/api/book-slot", acme, "", map[string]interface{}{
+		"name": "Ada", "user_id": 1, "interviewer_id": 1, "email": "ada@example.com",
+		"slot_date": date, "time_slot": "10:00 AM",
+	}), http.StatusOK, &created)
+	var booking pkg.Booking
+	if err := pkg.ForOrganization(db, acme.ID).First(&booking, created.BookingID).Error; err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(day.Year(), day.Month(), day.Day(), 10, 0, 0, 0, location)
+	if !booking.StartTime.Equal(start) || !booking.EndTime.Equal(start.Add(30*time.Minute)) {
+		t.Errorf("booked %v-%v, want %v-%v", booking.StartTime, booking.EndTime, start, start.Add(30*time.Minute))
+	}
+
+	book("10:00 AM", http.StatusConflict, "slot_already_booked")
+	book("02:00 PM", http.StatusBadRequest, "slot_unavailable")
+	if _, err := pkg.SaveHolidays(pkg.ForOrganization(db, acme.ID), pkg.DefaultHolidayCalendar, []pkg.Holiday{{Date: date, Name: "Founders Day"}}); err != nil {
+		t.Fatal(err)
+	}
+	book("11:00 AM", http.StatusBadRequest, "slot_unavailable")
+}
+
+func TestCancellingTwiceKeepsTheNextBooking(t *testing.T) {
+	s := newTestServer(t)
+	acme := s.organization("acme")
+	admin := s.token(auth.RoleAdmin, acme, 0)
+	slot := futureSlot(t, acme, 1, 2)
+	book := func(email string, status int) pkg.Booking {
+		t.Helper()
+		var created struct{ Booking pkg.Booking }
+		s.expect(s.do(http.MethodPost, "/api/v1/bookings", acme, "", map[string]interface{}{
+			"slot_id": slot.ID, "name": "Candidate", "email": email,
+		}), status, &created)
+		return created.Booking
+	}
+
+	first := book("ada@example.com", http.StatusOK)
+	path := fmt.Sprintf("/api/v1/bookings/%d", first.ID)
+	s.expect(s.do(http.MethodDelete, path, acme, admin, nil), http.StatusOK, nil)
+	book("bea@example.com", http.StatusOK)
+
+	// Cancelling the first booking again must not free the slot the second holds
+	s.expect(s.do(http.MethodDelete, path, acme, admin, nil), http.StatusOK, nil)
+	if err := pkg.ForOrganization(db, acme.ID).First(slot, slot.ID).Error; err != nil || !slot.IsBooked {
+		t.Errorf("slot released by a second cancellation (err %v)", err)
+	}
+	book("cy@example.com", http.StatusConflict)
+}
+
+func TestMigrationRecordsSlotKindsOfLegacyBookings(t *testing.T) {
+	newTestServer(t)
+	for _, column := range []string{"slot", "date"} {
+		if err := db.Exec(fmt.Sprintf("ALTER TABLE bookings ADD COLUMN %q text", column)).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Now().UTC().AddDate(0, 0, 2).Truncate(time.Hour)
+	if err := db.Exec("INSERT INTO availabilities (id, interviewer_id, start_time, end_time) VALUES (5, 1, ?, ?)", start, start.Add(time.Hour)).Error; err != nil {
+		t.Fatal(err)
+	}
+	legacy := map[uint]string{1: pkg.SlotKindTimeSlot, 2: pkg.SlotKindAvailability, 3: pkg.SlotKindSchedule}
+	for _, row := range []struct {
+		id, slotID uint
+		slot, date string
+	}{
+		{1, 7, "09:00 - 10:00", "2024-03-01"},
+		{2, 5, "", ""},
+		{3, 0, "11:00 - 12:00", "2024-03-01"},
+	} {
+		err := db.Exec("INSERT INTO bookings (id, organization_id, name, slot_id, slot_kind, status, slot, date) VALUES (?, 1, 'Ada', ?, '', 'booked', ?, ?)",
+			row.id, row.slotID, row.slot, row.date).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := pkg.AutoMigrateTables(db); err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range legacy {
+		var booking pkg.Booking
+		if err := pkg.AllOrganizations(db).First(&booking, id).Error; err != nil {
+			t.Fatal(err)
+		}
+		if booking.SlotKind != want || booking.StartTime.IsZero() {
+			t.Errorf("booking %d: slot kind %q, start %v; want %s with a start", id, booking.SlotKind, booking.StartTime, want)
+		}
+	}
+	if db.Migrator().HasColumn(&pkg.Booking{}, "slot") {
+		t.Error("legacy slot column kept after every booking got its times")
+	}
+}
+
+func TestBookingEventsFollowTheirBooking(t *testing.T) {
+	s := newTestServer(t)
+	acme, beta := s.organization("acme"), s.organization("beta")
+	slot := futureSlot(t, acme, 1, 2)
+	var created struct{ Booking pkg.Booking }
+	s.expect(s.do(http.MethodPost, "/api/v1/bookings", acme, "", map[string]interface{}{
+		"slot_id": slot.ID, "name": "Ada", "email": "ada@example.com",
+	}), http.StatusOK, &created)
+	scoped := pkg.ForOrganization(db, acme.ID)
+	var event pkg.Event
+	if err := scoped.Where("booking_id = ?", created.Booking.ID).First(&event).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Times and cancellation go through the booking, so the slot follows them
+	later, cancelled, noShow := event.StartTime.Add(time.Hour), pkg.EventCancelled, pkg.EventNoShow
+	for _, update := range []pkg.EventUpdate{{StartTime: &later}, {Status: &cancelled}} {
+		if _, err := pkg.UpdateEvent(scoped, event.ID, update); !errors.Is(err, pkg.ErrConflict) {
+			t.Errorf("update %+v of a booking's event: got %v, want a conflict", update, err)
+		}
+	}
+	if _, err := pkg.UpdateEvent(scoped, event.ID, pkg.EventUpdate{Status: &noShow}); err != nil {
+		t.Errorf("recording the outcome: %v", err)
+	}
+
+	// An event can only name a booking of its own organization
+	var problem struct{ Code string }
+	start := time.Now().UTC().AddDate(0, 0, 3).Truncate(time.Hour)
+	s.expect(s.do(http.MethodPost, "/api/v1/events", beta, s.token(auth.RoleAdmin, beta, 0), map[string]interface{}{
+		"booking_id": created.Booking.ID, "interviewer_id": 1, "booked_by": "Ada", "title": "Interview",
+		"start_time": start, "end_time": start.Add(time.Hour),
+	}), http.StatusNotFound, &problem)
+	if problem.Code != "booking_not_found" {
+		t.Errorf("code = %q, want booking_not_found", problem.Code)
+	}
+}
+
+func TestWaitlistOfferTokensAreStoredHashed(t *testing.T) {
+	s := newTestServer(t)
+	acme := s.organization("acme")
+	scoped := pkg.ForOrganization(db, acme.ID)
+	entry, _, err := pkg.JoinWaitlist(scoped, "Ada", "ada@example.com", 1, 0, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now().UTC().AddDate(0, 0, 2).Truncate(time.Hour)
+	offer, err := pkg.OfferFreedSlot(scoped, pkg.FreedSlot{Kind: pkg.SlotKindSchedule, InterviewerID: 1, StartTime: start, EndTime: start.Add(time.Hour)})
+	if err != nil || offer == nil {
+		t.Fatalf("no offer: %v", err)
+	}
+	// The stored hash does not claim the offer
+	s.expect(s.do(http.MethodGet, "/api/v1/waitlist/offers/"+offer.Token, acme, "", nil), http.StatusNotFound, nil)
+
+	// Offers made before tokens were hashed keep their emailed links
+	legacy := pkg.WaitlistOffer{
+		EntryID: entry.ID, SlotKind: pkg.SlotKindSchedule, InterviewerID: 1, StartTime: start.Add(time.Hour), EndTime: start.Add(2 * time.Hour),
+		Token: "plain-token", ExpiresAt: time.Now().Add(time.Hour), Status: pkg.OfferPending,
+	}
+	if err := scoped.Create(&legacy).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := pkg.AutoMigrateTables(db); err != nil {
+		t.Fatal(err)
+	}
+	s.expect(s.do(http.MethodGet, "/api/v1/waitlist/offers/plain-token", acme, "", nil), http.StatusOK, nil)
+	if err := scoped.First(&legacy, legacy.ID).Error; err != nil || legacy.Token == "plain-token" {
+		t.Errorf("legacy offer token kept in plain text (err %v)", err)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChangesRequireIfMatchByDefault(t *testing.T) {
+	s := newTestServer(t)
+	acme := s.organization("acme")
+	admin := s.token(auth.RoleAdmin, acme, 0)
+	slot := futureSlot(t, acme, 1, 2)
+	path := fmt.Sprintf("/api/v1/slots/%d", slot.ID)
+	move := map[string]interface{}{"start_time": slot.StartTime.Add(time.Hour), "end_time": slot.EndTime.Add(time.Hour)}
+
+	s.expect(s.do(http.MethodPatch, path, acme, admin, move), http.StatusPreconditionRequired, nil)
+
+	// Browsers may read the ETag to send it back
+	request := s.request(http.MethodGet, path, acme, "", nil)
+	request.Header.Set("Origin", "https://app.example.com")
+	response := s.serve(request)
+	s.expect(response, http.StatusOK, nil)
+	if exposed := response.Header().Get("Access-Control-Expose-Headers"); !strings.EqualFold(exposed, "ETag") {
+		t.Errorf("Access-Control-Expose-Headers = %q, want ETag", exposed)
+	}
+
+	request = s.request(http.MethodPatch, path, acme, admin, move)
+	request.Header.Set("If-Match", response.Header().Get("ETag"))
+	s.expect(s.serve(request), http.StatusOK, nil)
+}
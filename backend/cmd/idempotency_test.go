@@ -0,0 +1,49 @@
+package main
+
+import (
+	"BookingTimeSlot/backend/handler"
+	"BookingTimeSlot/backend/pkg"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestIdempotencyKeysOfAnonymousCallersAreSeparate(t *testing.T) {
+	s := newTestServer(t)
+	acme := s.organization("acme")
+
+	book := func(slot *pkg.TimeSlot, email, address string) pkg.Booking {
+		t.Helper()
+		request := s.request(http.MethodPost, "/api/v1/bookings", acme, "", map[string]interface{}{
+			"slot_id": slot.ID, "name": "Candidate", "email": email,
+		})
+		request.Header.Set(handler.IdempotencyKeyHeader, "booking-1")
+		request.RemoteAddr = address
+		var created struct{ Booking pkg.Booking }
+		s.expect(s.serve(request), http.StatusOK, &created)
+		return created.Booking
+	}
+
+	slot := futureSlot(t, acme, 1, 2)
+	first := book(slot, "ada@example.com", "203.0.113.7:4000")
+	if retried := book(slot, "ada@example.com", "203.0.113.7:4001"); retried.ID != first.ID {
+		t.Errorf("retry from the same caller booked %d, want the replayed booking %d", retried.ID, first.ID)
+	}
+	if other := book(futureSlot(t, acme, 2, 3), "bea@example.com", "198.51.100.9:4000"); other.ID == first.ID || other.Email != "bea@example.com" {
+		t.Errorf("another caller with the same key got booking %d of %s", other.ID, other.Email)
+	}
+}
+
+func TestIdempotencyBoundsTheBody(t *testing.T) {
+	s := newTestServer(t)
+	acme := s.organization("acme")
+
+	// A JSON string longer than any route accepts
+	request := s.request(http.MethodPost, "/api/v1/bookings", acme, "", strings.Repeat("a", 10<<20))
+	request.Header.Set(handler.IdempotencyKeyHeader, "too-large")
+	var problem struct{ Detail string }
+	s.expect(s.serve(request), http.StatusBadRequest, &problem)
+	if problem.Detail != "Failed to read request body" {
+		t.Errorf("detail = %q, want the body refused before it is read in full", problem.Detail)
+	}
+}
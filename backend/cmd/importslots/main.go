@@ -0,0 +1,110 @@
+package main
+
+import (
+	"BookingTimeSlot/backend/config"
+	"BookingTimeSlot/backend/pkg"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// importslots validates a CSV or JSON file of interviewer/start/end rows and,
+// with -commit, creates the time slots. Without -commit it is a dry run.
+//
+//	go run ./cmd/importslots -org 1 -file slots.csv
+//	go run ./cmd/importslots -org 1 -file slots.csv -commit
+func main() {
+	path := flag.String("file", "", "CSV or JSON file of slots; - reads standard input")
+	format := flag.String("format", "", "csv or json; defaults to the file extension")
+	organizationID := flag.Uint("org", 0, "organization ID the slots belong to")
+	commit := flag.Bool("commit", false, "create the slots if every row is valid")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if *path == "" || *organizationID == 0 {
+		log.Fatal("-file and -org are required")
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*path)), ".")
+	}
+
+	var input io.Reader = os.Stdin
+	if *path != "-" {
+		file, err := os.Open(*path)
+		if err != nil {
+			log.Fatalf("Failed to open %s: %v", *path, err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var rows []pkg.SlotImportRow
+	var err error
+	switch *format {
+	case "csv":
+		rows, err = pkg.ParseSlotImportCSV(input)
+	case "json":
+		rows, err = pkg.ParseSlotImportJSON(input)
+	default:
+		log.Fatalf("Unsupported format %q; use -format csv or json", *format)
+	}
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", *path, err)
+	}
+
+	// Database settings come from CONFIG_FILE and the environment, as for the server
+	cfg, err := config.Load(nil)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if err := cfg.Database.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	pkg.Connect(cfg.Database)
+	db := pkg.WithActor(pkg.ForOrganization(pkg.DB, *organizationID), "cli:importslots")
+	report, err := pkg.ImportTimeSlots(db, rows, *commit)
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	} else {
+		printReport(report)
+	}
+	if report.Invalid > 0 {
+		os.Exit(1)
+	}
+}
+
+// printReport writes one line per row and a summary
+func printReport(report *pkg.SlotImportReport) {
+	for _, row := range report.Rows {
+		line := fmt.Sprintf("row %d: %s", row.Row, row.Status)
+		if row.Status == pkg.SlotImportInvalid {
+			line += fmt.Sprintf(" (%s) %s", row.Code, row.Error)
+		} else {
+			line += fmt.Sprintf(" interviewer %d %s - %s", row.InterviewerID, row.StartTime.Format("2006-01-02 15:04"), row.EndTime.Format("15:04 MST"))
+		}
+		if row.SlotID != 0 {
+			line += fmt.Sprintf(" slot %d", row.SlotID)
+		}
+		fmt.Println(line)
+	}
+
+	switch {
+	case report.Committed:
+		fmt.Printf("Created %d of %d slots.\n", report.Created, report.Total)
+	case report.Invalid > 0:
+		fmt.Printf("%d of %d rows are invalid; nothing was created.\n", report.Invalid, report.Total)
+	default:
+		fmt.Printf("All %d rows are valid. Dry run; use -commit to create them.\n", report.Total)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/config"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+)
+
+// issuetoken prints a signed API token for an interviewer or admin.
+//
+//	go run ./cmd/issuetoken -role admin -sub alice@example.com -org 1 -ttl 720h
+func main() {
+	role := flag.String("role", auth.RoleInterviewer, "token role: admin or interviewer")
+	subject := flag.String("sub", "", "who the token belongs to, e.g. an email address")
+	interviewerID := flag.Uint("interviewer", 0, "interviewer ID for interviewer tokens")
+	organizationID := flag.Uint("org", 0, "organization ID the token is limited to; 0 for a platform admin")
+	ttl := flag.Duration("ttl", 30*24*time.Hour, "token lifetime")
+	flag.Parse()
+
+	// The secret comes from CONFIG_FILE and the environment, as for the server
+	cfg, err := config.Load(nil)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if err := cfg.Auth.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	auth.Secret = []byte(cfg.Auth.Secret)
+
+	if *subject == "" {
+		log.Fatal("-sub is required")
+	}
+	if *role == auth.RoleInterviewer && (*interviewerID == 0 || *organizationID == 0) {
+		log.Fatal("-interviewer and -org are required for interviewer tokens")
+	}
+
+	token, err := auth.IssueToken(auth.Claims{
+		Subject:        *subject,
+		Role:           *role,
+		InterviewerID:  *interviewerID,
+		OrganizationID: *organizationID,
+		ExpiresAt:      time.Now().Add(*ttl).Unix(),
+	})
+	if err != nil {
+		log.Fatalf("Failed to issue token: %v", err)
+	}
+	fmt.Println(token)
+}
@@ -1,71 +1,257 @@
 package main
 
 import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/config"
 	"BookingTimeSlot/backend/handler"
-	"log"
+	"BookingTimeSlot/backend/logging"
+	"BookingTimeSlot/backend/metrics"
+	"BookingTimeSlot/backend/notifications"
+	"BookingTimeSlot/backend/pkg"
+	"BookingTimeSlot/backend/ratelimit"
+	"BookingTimeSlot/backend/routes"
+	"BookingTimeSlot/backend/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 var db *gorm.DB
 
-func connectDatabase() {
-	if err := godotenv.Load(); err != nil {
-		log.Fatalf("Error loading .env file: %v", err)
-	}
-
-	dsn := "host=" + os.Getenv("DB_HOST") +
-		" user=" + os.Getenv("DB_USER") +
-		" password=" + os.Getenv("DB_PASSWORD") +
-		" dbname=" + os.Getenv("DB_NAME") +
-		" port=" + os.Getenv("DB_PORT") +
-		" sslmode=disable"
-
+func connectDatabase(cfg config.Database) {
+	// TranslateError reports unique violations as gorm.ErrDuplicatedKey
 	var err error
-	db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err = gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{TranslateError: true, Logger: logging.NewQueryLogger(cfg)})
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		logging.Fatal("Failed to connect to database", "error", err)
 	}
 
 	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatalf("Failed to get database instance: %v", err)
+		logging.Fatal("Failed to get database instance", "error", err)
 	}
 
 	if err = sqlDB.Ping(); err != nil {
-		log.Fatalf("Database unreachable: %v", err)
+		logging.Fatal("Database unreachable", "error", err)
+	}
+
+	if err := registerCallbacks(db); err != nil {
+		logging.Fatal("Failed to register database callbacks", "error", err)
+	}
+
+	slog.Info("Connected to the database successfully")
+}
+
+// registerCallbacks adds what every query of db needs beyond gorm itself
+func registerCallbacks(db *gorm.DB) error {
+	// Filter every ORM query by the request's organization
+	if err := pkg.RegisterTenantScope(db); err != nil {
+		return fmt.Errorf("tenant scope: %w", err)
+	}
+	// Record every scheduling change in the audit log
+	if err := pkg.RegisterAuditLog(db); err != nil {
+		return fmt.Errorf("audit log: %w", err)
+	}
+	// Count changes to versioned rows, for If-Match
+	if err := pkg.RegisterVersioning(db); err != nil {
+		return fmt.Errorf("versioning: %w", err)
 	}
+	// Trace queries run for a traced request
+	if err := tracing.RegisterQueryTracing(db); err != nil {
+		return fmt.Errorf("query tracing: %w", err)
+	}
+	return nil
+}
 
-	log.Println("Connected to the database successfully.")
+func migrateDatabase(cfg config.Database) {
+	if err := pkg.AutoMigrateTables(db); err != nil {
+		logging.Fatal("Failed to migrate database", "error", err)
+	}
+
+	// Availability windows and the legacy per-day availability table are tenant data too
+	if err := db.AutoMigrate(&handler.Availability{}); err != nil {
+		logging.Fatal("Failed to migrate availabilities", "error", err)
+	}
+	tables := []string{"availabilities"}
+	if db.Migrator().HasTable("availability") {
+		if err := db.Exec(`ALTER TABLE availability ADD COLUMN IF NOT EXISTS organization_id bigint NOT NULL DEFAULT 0`).Error; err != nil {
+			logging.Fatal("Failed to migrate availability", "error", err)
+		}
+		tables = append(tables, "availability")
+	}
+	if err := pkg.AssignDefaultOrganization(db, tables...); err != nil {
+		logging.Fatal("Failed to assign organizations", "error", err)
+	}
+
+	// Optionally have PostgreSQL reject overlapping windows too; the API checks either way
+	if cfg.OverlapConstraint {
+		for _, table := range []pkg.OverlapTable{pkg.TimeSlotOverlapTable, handler.AvailabilityOverlapTable} {
+			if err := pkg.EnableOverlapConstraint(db, table); err != nil {
+				logging.Fatal("Failed to enable overlap constraint (list overlaps at /api/v1/admin/overlaps)", "error", err)
+			}
+		}
+	}
+
+	// Every organization starts from the bundled holidays; uploads add their own calendars
+	organizations, err := pkg.ListOrganizations(db)
+	if err != nil {
+		logging.Fatal("Failed to list organizations", "error", err)
+	}
+	for _, organization := range organizations {
+		count, err := pkg.LoadBundledHolidays(pkg.ForOrganization(db, organization.ID))
+		if err != nil {
+			logging.Fatal("Failed to load bundled holidays", "organization", organization.Slug, "error", err)
+		}
+		slog.Info("Loaded bundled holidays", "organization", organization.Slug, "count", count)
+	}
+}
+
+// configure hands each package its settings: waitlist offers, email
+// verification, the API token secret, the manage-booking link base URL, the
+// base domain under which subdomains select an organization, the mail server
+// and the booking limits
+func configure(cfg *config.Config) {
+	pkg.WaitlistHoldDuration = cfg.Scheduling.WaitlistHold()
+	pkg.WaitlistClaimBaseURL = cfg.Scheduling.WaitlistClaimURL
+	pkg.VerifyBookingEmails = cfg.Scheduling.VerifyEmails
+	pkg.VerificationHoldDuration = cfg.Scheduling.VerificationHold()
+	pkg.VerifyBookingBaseURL = cfg.Scheduling.VerifyBookingURL
+	pkg.ManageBookingBaseURL = cfg.Scheduling.ManageBookingURL
+	auth.Secret = []byte(cfg.Auth.Secret)
+	handler.TenantBaseDomain = cfg.HTTP.TenantBaseDomain
+	pkg.IdempotencyWindow = cfg.HTTP.IdempotencyWindow
+	handler.RequireIfMatch = cfg.HTTP.RequireIfMatch
+	notifications.Server = cfg.SMTP
+	if !cfg.SMTP.Configured() {
+		slog.Warn("SMTP credentials are not set; emails will be logged and dropped")
+	}
+
+	// Public booking routes are rate limited in this process; the store can be
+	// swapped for a shared one when running several instances
+	limits := cfg.RateLimit
+	store := ratelimit.NewMemoryStore()
+	handler.BookingIPLimiter = ratelimit.New("ip", ratelimit.PerHour(limits.IPPerHour, limits.IPBurst), store)
+	handler.BookingEmailLimiter = ratelimit.New("email", ratelimit.PerHour(limits.EmailPerHour, limits.EmailBurst), store)
+	handler.BookingInterviewerLimiter = ratelimit.New("interviewer", ratelimit.PerHour(limits.InterviewerPerHour, limits.InterviewerBurst), store)
+	pkg.MaxActiveBookingsPerEmail = cfg.Scheduling.MaxActiveBookings
+}
+
+// runHoldExpiry periodically passes expired waitlist offers to the next
+// candidate, releases the slots of bookings whose email was never verified
+// and purges idempotency keys past their window
+func runHoldExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if count, err := pkg.ExpireWaitlistOffers(db); err != nil {
+				slog.Error("Waitlist expiry failed", "error", err)
+			} else if count > 0 {
+				slog.Info("Expired waitlist offers", "count", count)
+			}
+			if count, err := pkg.ReleaseUnverifiedBookings(db); err != nil {
+				slog.Error("Releasing unverified bookings failed", "error", err)
+			} else if count > 0 {
+				slog.Info("Released unverified bookings", "count", count)
+			}
+			if _, err := pkg.PurgeIdempotencyKeys(db); err != nil {
+				slog.Error("Purging idempotency keys failed", "error", err)
+			}
+		}
+	}
+}
+
+// migratedModels lists every model migrateDatabase creates a table for
+func migratedModels() []interface{} {
+	models := make([]interface{}, 0, len(pkg.MigratedModels)+1)
+	models = append(models, pkg.MigratedModels...)
+	return append(models, &handler.Availability{})
+}
+
+// registerMetrics exposes the connection pool and the email queue on /metrics
+func registerMetrics(sqlDB *sql.DB) {
+	metrics.RegisterDBStats(sqlDB)
+	metrics.NewGaugeFunc("notification_queue_depth", "Emails waiting to be sent.", func() float64 {
+		return float64(notifications.QueueDepth())
+	})
+}
+
+// corsMiddleware lets the configured origins call the API from a browser
+func corsMiddleware(cfg config.CORS) gin.HandlerFunc {
+	corsConfig := cors.DefaultConfig()
+	if cfg.AllowsAllOrigins() {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = cfg.AllowedOrigins
+	}
+	corsConfig.AllowHeaders = cfg.AllowedHeaders
+	corsConfig.ExposeHeaders = cfg.ExposedHeaders
+	return cors.New(corsConfig)
 }
 
 func dbMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Set the db instance in the context
-		c.Set("db", db)
+		// Set the db instance in the context; its queries carry the request's log
+		// fields and trace, but finish even if the client goes away
+		c.Set("db", db.WithContext(context.WithoutCancel(c.Request.Context())))
 		c.Next()
 	}
 }
 
 func GetAvailableSlots(c *gin.Context) {
-	interviewerID := c.Param("interviewer_id")
+	db := c.MustGet("db").(*gorm.DB)
+	interviewerID, err := strconv.Atoi(c.Param("interviewer_id"))
 	dateParam := c.Param("date")
 
-	if interviewerID == "" || dateParam == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Interviewer ID and date are required"})
+	if err != nil || interviewerID <= 0 {
+		c.Error(pkg.Validation("invalid_interviewer_id", "Invalid interviewer ID"))
 		return
 	}
 
 	if _, err := time.Parse("2006-01-02", dateParam); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD."})
+		c.Error(pkg.Validation("invalid_date", "Invalid date format. Use YYYY-MM-DD."))
+		return
+	}
+
+	// Holidays and blackouts remove the date, replacement hours stand in for it
+	rule, err := pkg.ResolveDateRule(db, uint(interviewerID), dateParam)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if rule.Blocked {
+		c.JSON(http.StatusOK, []map[string]interface{}{})
+		return
+	}
+	if rule.Override != nil {
+		c.JSON(http.StatusOK, []map[string]interface{}{{
+			"id":             0,
+			"interviewer_id": interviewerID,
+			"available_date": dateParam,
+			"start_time":     rule.Override.StartTime,
+			"end_time":       rule.Override.EndTime,
+		}})
 		return
 	}
 
@@ -75,9 +261,10 @@ func GetAvailableSlots(c *gin.Context) {
 		AvailableDate time.Time `json:"available_date"`
 	}
 
-	query := `SELECT id, interviewer_id, available_date FROM availability WHERE interviewer_id = ? AND available_date = ?`
-	if err := db.Raw(query, interviewerID, dateParam).Scan(&slots).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching available slots"})
+	// Raw SQL bypasses the tenant scope, so filter by organization explicitly
+	query := `SELECT id, interviewer_id, available_date FROM availability WHERE organization_id = ? AND interviewer_id = ? AND available_date = ?`
+	if err := db.Raw(query, c.GetUint("organization_id"), interviewerID, dateParam).Scan(&slots).Error; err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -94,14 +281,15 @@ func GetAvailableSlots(c *gin.Context) {
 }
 
 func GetAvailabilityByDate(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
 	dateParam := c.Query("date")
 	if dateParam == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Date is required"})
+		c.Error(pkg.Validation("date_required", "Date is required"))
 		return
 	}
 
 	if _, err := time.Parse("2006-01-02", dateParam); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD."})
+		c.Error(pkg.Validation("invalid_date", "Invalid date format. Use YYYY-MM-DD."))
 		return
 	}
 
@@ -111,69 +299,152 @@ func GetAvailabilityByDate(c *gin.Context) {
 		AvailableDate time.Time `json:"available_date"`
 	}
 
-	query := `SELECT id, interviewer_id, available_date FROM availability WHERE available_date = ?`
-	if err := db.Raw(query, dateParam).Scan(&slots).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching slots"})
+	// Organization-wide holidays block every interviewer
+	holiday, err := pkg.FindHoliday(db, dateParam)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if holiday != nil {
+		c.JSON(http.StatusOK, []map[string]interface{}{})
 		return
 	}
 
-	result := make([]map[string]interface{}, len(slots))
-	for i, slot := range slots {
-		result[i] = gin.H{
+	overrides, err := pkg.OverridesForDate(db, dateParam)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	query := `SELECT id, interviewer_id, available_date FROM availability WHERE organization_id = ? AND available_date = ?`
+	if err := db.Raw(query, c.GetUint("organization_id"), dateParam).Scan(&slots).Error; err != nil {
+		c.Error(err)
+		return
+	}
+
+	result := make([]map[string]interface{}, 0, len(slots))
+	for _, slot := range slots {
+		entry := gin.H{
 			"id":             slot.ID,
 			"interviewer_id": slot.InterviewerID,
 			"available_date": slot.AvailableDate.Format("2006-01-02"),
 		}
+
+		// Blackout overrides remove the interviewer, replacement hours are attached
+		if override, ok := overrides[uint(slot.InterviewerID)]; ok {
+			if override.Unavailable {
+				continue
+			}
+			entry["start_time"] = override.StartTime
+			entry["end_time"] = override.EndTime
+			delete(overrides, uint(slot.InterviewerID))
+		}
+		result = append(result, entry)
+	}
+
+	// Interviewers working an extra day only appear through their override
+	for interviewerID, override := range overrides {
+		if override.Unavailable {
+			continue
+		}
+		result = append(result, gin.H{
+			"id":             0,
+			"interviewer_id": interviewerID,
+			"available_date": dateParam,
+			"start_time":     override.StartTime,
+			"end_time":       override.EndTime,
+		})
 	}
 
 	c.JSON(http.StatusOK, result)
 }
 
+// bookSlotRequest books a legacy availability slot by date and 12-hour time
+type bookSlotRequest struct {
+	Name        string            `json:"name" binding:"required"`
+	UserID      int               `json:"user_id" binding:"required"`
+	Interviewer int               `json:"interviewer_id" binding:"required"`
+	TimeSlot    string            `json:"time_slot" binding:"required"` // e.g. "09:30 AM"
+	SlotDate    string            `json:"slot_date" binding:"required"`
+	Email       string            `json:"email"`
+	EventTypeID uint              `json:"event_type_id"`
+	Answers     map[string]string `json:"answers"`
+}
+
 func BookTimeSlot(c *gin.Context) {
-	var req struct {
-		Name        string `json:"name" binding:"required"`
-		UserID      int    `json:"user_id" binding:"required"`
-		Interviewer int    `json:"interviewer_id" binding:"required"`
-		TimeSlot    string `json:"time_slot" binding:"required"`
-		SlotDate    string `json:"slot_date" binding:"required"`
-	}
+	db := c.MustGet("db").(*gorm.DB)
+	var req bookSlotRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking data"})
+		c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
-	// Validate time in 12-hour format (hh:mm AM/PM)
-	if _, err := time.Parse("03:04 PM", req.TimeSlot); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time format. Use hh:mm AM/PM."})
+	// Email is optional on this legacy route, but must be valid when given, and
+	// given when bookings wait for email verification
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" && pkg.VerifyBookingEmails {
+		c.Error(pkg.Validation("email_required", "Email is required to verify the booking."))
 		return
 	}
+	if req.Email != "" {
+		if err := pkg.ValidateEmail(req.Email); err != nil {
+			c.Error(err)
+			return
+		}
+	}
 
 	// Validate date in YYYY-MM-DD format
 	if _, err := time.Parse("2006-01-02", req.SlotDate); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD."})
+		c.Error(pkg.Validation("invalid_date", "Invalid date format. Use YYYY-MM-DD."))
+		return
+	}
+
+	db = handler.LogFields(c, db, "interviewer_id", req.Interviewer)
+	if err := handler.LimitBooking(c, req.Email, uint(req.Interviewer)); err != nil {
+		c.Error(err)
 		return
 	}
 
-	var existingBooking int
-	query := `SELECT COUNT(*) FROM bookings WHERE interviewer_id = ? AND time_slot = ? AND slot_date = ?`
-	if err := db.Raw(query, req.Interviewer, req.TimeSlot, req.SlotDate).Scan(&existingBooking).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error during slot check"})
+	// Validate custom booking form fields for the event type
+	answers, err := pkg.ValidateAnswers(db, req.EventTypeID, req.Answers)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	if existingBooking > 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "This slot is already booked."})
+	clock, err := time.Parse("03:04 PM", req.TimeSlot)
+	if err != nil {
+		c.Error(pkg.Validation("invalid_time", "Invalid time format. Use hh:mm AM/PM."))
 		return
 	}
+	// The time is the interviewer's wall clock, as the schedule is
+	start, end, err := pkg.ScheduleSlotAt(db, uint(req.Interviewer), req.SlotDate, clock.Format("15:04"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	booking := pkg.Booking{
+		Name:          req.Name,
+		Email:         req.Email,
+		UserID:        req.UserID,
+		InterviewerID: uint(req.Interviewer),
+		EventTypeID:   req.EventTypeID,
+		StartTime:     start,
+		EndTime:       end,
+		Answers:       answers,
+	}
 
-	insertQuery := `INSERT INTO bookings (name, user_id, interviewer_id, time_slot, booking_date, slot_date) VALUES (?, ?, ?, ?, ?, ?)`
-	if err := db.Exec(insertQuery, req.Name, req.UserID, req.Interviewer, req.TimeSlot, time.Now().Format("2006-01-02"), req.SlotDate).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error while booking slot"})
+	// The interval must still be offered when the booking is saved, as on v1
+	err = pkg.BookScheduleSlot(db, &booking)
+	if err != nil {
+		c.Error(err)
 		return
 	}
+	handler.LogFields(c, db, "booking_id", booking.ID)
+	slog.InfoContext(c.Request.Context(), "Booking created")
 
-	c.JSON(http.StatusOK, gin.H{"message": "Slot successfully booked"})
+	c.JSON(http.StatusOK, gin.H{"message": "Slot successfully booked", "booking_id": booking.ID, "status": booking.Status, "answers": answers})
 }
 
 func serveReactApp(w http.ResponseWriter, r *http.Request) {
@@ -184,37 +455,137 @@ func serveReactApp(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, path)
 }
 
-func getPort() string {
-	if port := os.Getenv("PORT"); port != "" {
-		return ":" + port
+func main() {
+	// Settings come from defaults, CONFIG_FILE, the environment (optionally
+	// loaded from .env) and flags; see the config package
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		os.Exit(2)
 	}
-	return ":8080"
-}
 
-func main() {
-	connectDatabase()
+	logging.Setup(cfg.Logging)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		logging.Fatal("Failed to set up tracing", "error", err)
+	}
+
+	// SIGINT or SIGTERM starts a graceful shutdown
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	connectDatabase(cfg.Database)
+	migrateDatabase(cfg.Database)
 	sqlDB, _ := db.DB()
-	defer sqlDB.Close()
 
-	r := gin.Default()
-	r.SetTrustedProxies(nil)
-	r.Use(cors.Default(), dbMiddleware()) // Attach the db middleware here
+	// Background workers: email delivery and the expiry of waitlist offers and unverified bookings
+	configure(cfg)
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		notifications.RunWorker(workerCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		runHoldExpiry(workerCtx, cfg.Scheduling.HoldExpiryInterval)
+	}()
+	registerMetrics(sqlDB)
+
+	server := newServer(cfg.HTTP, setUpRouter(cfg))
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			logging.Fatal("Server stopped", "error", err)
+		}
+	}()
+	slog.Info("Server running", "address", server.Addr)
+
+	<-signals.Done()
+	stopSignals() // a second signal kills the process right away
+	slog.Info("Shutting down", "timeout", cfg.HTTP.ShutdownTimeout.String())
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	// Finish requests first, as they queue emails; then the workers, the
+	// pending spans and finally the database pool
+	shutdownServer(ctx, server)
+	stopWorkers(ctx, cancelWorkers, &workers)
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+	if err := sqlDB.Close(); err != nil {
+		slog.Error("Failed to close database", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+
+// setUpRouter registers the middleware and every route
+func setUpRouter(cfg *config.Config) *gin.Engine {
+	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		logging.Fatal("Invalid trusted proxies", "error", err)
+	}
+
+	// Access logs, request IDs, traces, probes and metrics come before the tenant middleware, which needs the database
+	r.Use(logging.Middleware(), logging.Recovery(), handler.RequestID(), tracing.Middleware(), metrics.Middleware())
+	r.GET("/healthz", handler.Health)
+	r.GET("/readyz", handler.Readiness(db, migratedModels()...))
+	r.GET("/metrics", metrics.Handler())
+
+	r.Use(corsMiddleware(cfg.CORS), handler.ProblemDetails(), dbMiddleware(), auth.Middleware(), handler.ResolveOrganization(), handler.AuditActor(), handler.Idempotency()) // Attach the error, db, auth, tenant, audit and idempotency middleware here
 
 	api := r.Group("/api")
 	{
-		api.POST("/interviewer/availability", handler.SetInterviewerAvailability)
-		api.GET("/availability/:interviewer_id", handler.GetInterviewerAvailability)
-		api.GET("/availability/:interviewer_id/:date", GetAvailableSlots)
-		api.GET("/availability", GetAvailabilityByDate)
-		api.POST("/book-slot", BookTimeSlot)
-		api.DELETE("/bookings/:id", handler.CancelBooking)
+		api.POST("/interviewer/availability", handler.Deprecated("/api/v1/availability"), auth.RequireRole(auth.RoleInterviewer, auth.RoleAdmin), handler.SetInterviewerAvailability)
+		api.GET("/availability/:interviewer_id", handler.Deprecated("/api/v1/interviewers/:interviewer_id/availability"), handler.GetInterviewerAvailability)
+		api.GET("/availability/:interviewer_id/:date", handler.Deprecated("/api/v1/interviewers/:interviewer_id/availability"), GetAvailableSlots)
+		api.GET("/availability", handler.Deprecated("/api/v1/availability"), GetAvailabilityByDate)
+		api.GET("/availability/range", handler.Deprecated("/api/v1/availability/range"), handler.GetAvailabilityRange)
+		api.POST("/book-slot", handler.Deprecated("/api/v1/bookings"), handler.CountBookingAttempts(), handler.ProtectBooking(), BookTimeSlot)
+		api.DELETE("/bookings/:id", handler.Deprecated("/api/v1/bookings/:id"), auth.RequireRole(auth.RoleInterviewer, auth.RoleAdmin), handler.CancelBooking)
+		api.GET("/interviewer/:interviewer_id/overrides", handler.Deprecated("/api/v1/interviewers/:interviewer_id/overrides"), handler.GetAvailabilityOverrides)
+		api.POST("/interviewer/:interviewer_id/overrides", handler.Deprecated("/api/v1/interviewers/:interviewer_id/overrides"), auth.RequireRole(auth.RoleInterviewer, auth.RoleAdmin), handler.SetAvailabilityOverride)
+		api.DELETE("/overrides/:id", handler.Deprecated("/api/v1/overrides/:id"), auth.RequireRole(auth.RoleInterviewer, auth.RoleAdmin), handler.DeleteAvailabilityOverride)
+		api.GET("/holidays", handler.Deprecated("/api/v1/holidays"), handler.GetHolidays)
+		api.GET("/interviewer/:interviewer_id/schedule", handler.Deprecated("/api/v1/interviewers/:interviewer_id/schedules"), handler.GetScheduleTemplates)
+		api.POST("/interviewer/:interviewer_id/schedule", handler.Deprecated("/api/v1/interviewers/:interviewer_id/schedules"), auth.RequireRole(auth.RoleInterviewer, auth.RoleAdmin), handler.CreateScheduleTemplate)
+		api.DELETE("/schedule/:id", handler.Deprecated("/api/v1/schedules/:id"), auth.RequireRole(auth.RoleInterviewer, auth.RoleAdmin), handler.DeleteScheduleTemplate)
+		api.GET("/event-types", handler.Deprecated("/api/v1/event-types"), handler.GetEventTypes)
+		api.POST("/event-types", handler.Deprecated("/api/v1/event-types"), auth.RequireRole(auth.RoleInterviewer, auth.RoleAdmin), handler.CreateEventType)
+		api.GET("/event-types/:id/questions", handler.Deprecated("/api/v1/event-types/:id/questions"), handler.GetIntakeQuestions)
+		api.POST("/event-types/:id/questions", handler.Deprecated("/api/v1/event-types/:id/questions"), auth.RequireRole(auth.RoleInterviewer, auth.RoleAdmin), handler.CreateIntakeQuestion)
+		api.DELETE("/questions/:id", handler.Deprecated("/api/v1/questions/:id"), auth.RequireRole(auth.RoleInterviewer, auth.RoleAdmin), handler.DeleteIntakeQuestion)
+		api.GET("/interviewers", handler.Deprecated("/api/v1/interviewers"), handler.GetInterviewers)
+		api.POST("/interviewers", handler.Deprecated("/api/v1/interviewers"), auth.RequireRole(auth.RoleInterviewer, auth.RoleAdmin), handler.CreateInterviewer)
+		api.GET("/bookings/:id", handler.Deprecated("/api/v1/bookings/:id"), auth.RequireRole(auth.RoleInterviewer, auth.RoleAdmin), handler.GetBooking)
+		api.POST("/waitlist", handler.Deprecated("/api/v1/waitlist"), handler.JoinWaitlist)
+		api.DELETE("/waitlist/:id", handler.Deprecated("/api/v1/waitlist/:id"), handler.LeaveWaitlist)
+		api.GET("/waitlist/offers/:token", handler.Deprecated("/api/v1/waitlist/offers/:token"), handler.GetWaitlistOffer)
+		api.POST("/waitlist/offers/:token/claim", handler.Deprecated("/api/v1/waitlist/offers/:token/claim"), handler.CountBookingAttempts(), handler.ProtectBooking(), handler.ClaimWaitlistOffer)
+		api.POST("/holidays", handler.Deprecated("/api/v1/holidays"), auth.RequireRole(auth.RoleAdmin), handler.UploadHolidays)
+		api.GET("/manage/:token", handler.Deprecated("/api/v1/manage/:token"), handler.GetManagedBooking)
+		api.POST("/manage/:token/cancel", handler.Deprecated("/api/v1/manage/:token/cancel"), handler.CancelManagedBooking)
+		api.POST("/manage/:token/reschedule", handler.Deprecated("/api/v1/manage/:token/reschedule"), handler.RescheduleManagedBooking)
+		api.GET("/organizations", handler.Deprecated("/api/v1/organizations"), auth.RequireRole(auth.RoleAdmin), handler.GetOrganizations)
+		api.POST("/organizations", handler.Deprecated("/api/v1/organizations"), auth.RequireRole(auth.RoleAdmin), handler.CreateOrganization)
+		api.GET("/admin/audit-logs", handler.Deprecated("/api/v1/admin/audit-logs"), auth.RequireRole(auth.RoleAdmin), handler.GetAuditLogs)
+		api.GET("/openapi.json", apiSpec.Handler())
 	}
 
+	// Versioned API; the routes above and the unversioned /bookings and
+	// /availability are deprecated adapters of it
+	routes.SetUpV1Routes(r)
+	routes.SetUpRoutes(r)
+
 	r.NoRoute(func(c *gin.Context) {
 		serveReactApp(c.Writer, c.Request)
 	})
-
-	port := getPort()
-	log.Printf("Server running on %s", port)
-	log.Fatal(r.Run(port))
+	return r
 }
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/config"
+	"BookingTimeSlot/backend/handler"
+	"BookingTimeSlot/backend/pkg"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testServer is the router backed by a freshly migrated in-memory SQLite
+// database, which stands in for PostgreSQL.
+type testServer struct {
+	t      *testing.T
+	router *gin.Engine
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var err error
+	db, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{TranslateError: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1) // every connection to :memory: is a database of its own
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := registerCallbacks(db); err != nil {
+		t.Fatal(err)
+	}
+	migrateDatabase(config.Database{})
+
+	auth.Secret = []byte("test secret")
+	handler.TenantBaseDomain = "example.com"
+	cfg := config.Default()
+	return &testServer{t: t, router: setUpRouter(&cfg)}
+}
+
+// organization creates a tenant reachable at <slug>.example.com
+func (s *testServer) organization(slug string) *pkg.Organization {
+	s.t.Helper()
+	organization, err := pkg.CreateOrganization(db, slug, slug)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return organization
+}
+
+// token issues a bearer token for role in organization
+func (s *testServer) token(role string, organization *pkg.Organization, interviewerID uint) string {
+	s.t.Helper()
+	token, err := auth.IssueToken(auth.Claims{
+		Subject:        role + "@" + organization.Slug,
+		Role:           role,
+		InterviewerID:  interviewerID,
+		OrganizationID: organization.ID,
+		ExpiresAt:      time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return token
+}
+
+// do sends a request to organization's host; token may be empty, body is JSON
+func (s *testServer) do(method, path string, organization *pkg.Organization, token string, body interface{}) *httptest.ResponseRecorder {
+	s.t.Helper()
+	return s.serve(s.request(method, path, organization, token, body))
+}
+
+// request builds the request do sends, for tests that adjust it first
+func (s *testServer) request(method, path string, organization *pkg.Organization, token string, body interface{}) *http.Request {
+	s.t.Helper()
+	payload := ""
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		payload = string(encoded)
+	}
+
+	request := httptest.NewRequest(method, path, strings.NewReader(payload))
+	request.Host = organization.Slug + ".example.com"
+	request.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	return request
+}
+
+// serve sends request to the router
+func (s *testServer) serve(request *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// expect fails the test unless the response has status, and decodes its body into out
+func (s *testServer) expect(response *httptest.ResponseRecorder, status int, out interface{}) {
+	s.t.Helper()
+	if response.Code != status {
+		s.t.Fatalf("got status %d, want %d: %s", response.Code, status, response.Body)
+	}
+	if out != nil {
+		if err := json.Unmarshal(response.Body.Bytes(), out); err != nil {
+			s.t.Fatalf("decoding %s: %v", response.Body, err)
+		}
+	}
+}
+
+// futureSlot stores an open time slot of the interviewer starting in days
+func futureSlot(t *testing.T, organization *pkg.Organization, interviewerID uint, days int) *pkg.TimeSlot {
+	t.Helper()
+	start := time.Now().UTC().Truncate(time.Hour).AddDate(0, 0, days)
+	slot := pkg.TimeSlot{InterviewerID: interviewerID, StartTime: start, EndTime: start.Add(time.Hour)}
+	if err := pkg.ForOrganization(db, organization.ID).Create(&slot).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &slot
+}
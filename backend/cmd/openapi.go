@@ -0,0 +1,293 @@
+package main
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/handler"
+	"BookingTimeSlot/backend/openapi"
+	"BookingTimeSlot/backend/pkg"
+	"BookingTimeSlot/backend/routes"
+	"net/http"
+)
+
+var (
+	staff = []string{auth.RoleInterviewer, auth.RoleAdmin}
+	admin = []string{auth.RoleAdmin}
+
+	dateQuery      = openapi.Parameter{Name: "date", Required: true, Description: "YYYY-MM-DD"}
+	fromToQuery    = []openapi.Parameter{{Name: "from", Description: "YYYY-MM-DD"}, {Name: "to", Description: "YYYY-MM-DD"}}
+	messageOnly    = openapi.Object{"message": ""}
+	mergeQuery     = openapi.Parameter{Name: "merge", Type: "boolean", Description: "fold overlapping or adjacent open windows into this one instead of rejecting the overlap"}
+	legacyDayEntry = openapi.Object{"id": 0, "interviewer_id": 0, "available_date": "", "start_time": "", "end_time": ""}
+
+	availabilityRangeQuery = []openapi.Parameter{
+		{Name: "from", Required: true, Description: "YYYY-MM-DD"},
+		{Name: "to", Required: true, Description: "YYYY-MM-DD"},
+		{Name: "tz", Description: "IANA time zone of the returned days"},
+		{Name: "interviewer_id", Description: "repeated or comma-separated interviewer IDs"},
+		{Name: "event_type", Description: "event type ID or slug; sets the slot length"},
+		{Name: "summary", Type: "boolean", Description: "only count open slots per day"},
+		{Name: "limit", Type: "integer", Description: "days per page"},
+		{Name: "cursor", Description: "next_cursor of the previous page"},
+	}
+	auditLogQuery = []openapi.Parameter{
+		{Name: "actor"}, {Name: "action"}, {Name: "entity_type"}, {Name: "entity_id"}, {Name: "request_id"},
+		{Name: "from", Description: "RFC 3339 or YYYY-MM-DD"},
+		{Name: "to", Description: "RFC 3339 or YYYY-MM-DD; exclusive"},
+		{Name: "limit", Type: "integer"}, {Name: "offset", Type: "integer"},
+	}
+	fromToTimeQuery = []openapi.Parameter{
+		{Name: "from", Description: "RFC 3339 or YYYY-MM-DD; defaults to four weeks before to"},
+		{Name: "to", Description: "RFC 3339 or YYYY-MM-DD; exclusive, defaults to now"},
+	}
+)
+
+// apiSpec documents every route main registers. TestSpecDocumentsRoutes fails
+// when the two disagree, so add an operation here with each new route.
+var apiSpec = &openapi.Spec{
+	Title:      "BookingTimeSlot API",
+	Version:    "1.0.0",
+	Error:      handler.Problem{},
+	Operations: append(append(v1Operations, deprecated(legacyOperations)...), metaOperations...),
+}
+
+// v1Operations are the versioned routes of routes.SetUpV1Routes
+var v1Operations = []openapi.Operation{
+	// Interviewers and what they offer
+	{Method: http.MethodGet, Path: "/api/v1/interviewers", Tag: "interviewers", Summary: "List interviewers",
+		Response: openapi.Object{"interviewers": []pkg.Interviewer{}}},
+	{Method: http.MethodPost, Path: "/api/v1/interviewers", Tag: "interviewers", Summary: "Create an interviewer", Roles: staff,
+		Request: handler.InterviewerRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.Interviewer{}}},
+	{Method: http.MethodGet, Path: "/api/v1/interviewers/:interviewer_id/availability", Tag: "availability", Summary: "Get an interviewer's availability for a date, after holidays and overrides",
+		Query: []openapi.Parameter{dateQuery}, Response: openapi.Object{"availability": []handler.Availability{}, "blocked": false, "reason": ""}},
+	{Method: http.MethodGet, Path: "/api/v1/interviewers/:interviewer_id/overrides", Tag: "availability", Summary: "List an interviewer's date overrides",
+		Query: fromToQuery, Response: openapi.Object{"overrides": []pkg.AvailabilityOverride{}}},
+	{Method: http.MethodPost, Path: "/api/v1/interviewers/:interviewer_id/overrides", Tag: "availability", Summary: "Set replacement hours for a date or block it", Roles: staff,
+		Request: handler.OverrideRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.AvailabilityOverride{}}},
+	{Method: http.MethodDelete, Path: "/api/v1/overrides/:id", Tag: "availability", Summary: "Delete a date override", Roles: staff, Response: messageOnly},
+	{Method: http.MethodGet, Path: "/api/v1/interviewers/:interviewer_id/schedules", Tag: "availability", Summary: "List an interviewer's weekly schedule",
+		Response: openapi.Object{"templates": []pkg.ScheduleTemplate{}}},
+	{Method: http.MethodPost, Path: "/api/v1/interviewers/:interviewer_id/schedules", Tag: "availability", Summary: "Add a weekly schedule window", Roles: staff,
+		Request: handler.ScheduleTemplateRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.ScheduleTemplate{}}},
+	{Method: http.MethodDelete, Path: "/api/v1/schedules/:id", Tag: "availability", Summary: "Delete a weekly schedule window", Roles: staff, Response: messageOnly},
+	{Method: http.MethodGet, Path: "/api/v1/holidays", Tag: "availability", Summary: "List holidays",
+		Query: fromToQuery, Response: openapi.Object{"holidays": []pkg.Holiday{}}},
+	{Method: http.MethodPost, Path: "/api/v1/holidays", Tag: "availability", Summary: "Import a holiday calendar from CSV (date,name) or ICS", Roles: admin,
+		Form: openapi.Object{"file": openapi.File{}, "calendar": ""}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "calendar": "", "count": 0}},
+
+	// Open slots
+	{Method: http.MethodGet, Path: "/api/v1/availability", Tag: "availability", Summary: "List open slots generated from weekly schedules",
+		Query:    []openapi.Parameter{dateQuery, {Name: "interviewer_id", Type: "integer"}},
+		Response: openapi.Object{"date": "", "available_slots": []pkg.AvailableSlot{}, "total_available": 0}},
+	{Method: http.MethodPost, Path: "/api/v1/availability", Tag: "availability", Summary: "Set an interviewer's availability window", Roles: staff,
+		Query:   []openapi.Parameter{mergeQuery},
+		Request: handler.Availability{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": handler.Availability{}}},
+	{Method: http.MethodGet, Path: "/api/v1/availability/:id", Tag: "availability", Summary: "Get an availability window; its ETag is the version for If-Match",
+		Response: openapi.Object{"availability": handler.Availability{}}},
+	{Method: http.MethodPatch, Path: "/api/v1/availability/:id", Tag: "availability", Summary: "Move an open availability window, if it still matches If-Match", Roles: staff,
+		Query:   []openapi.Parameter{mergeQuery},
+		Request: handler.AvailabilityUpdateRequest{}, Response: openapi.Object{"message": "", "data": handler.Availability{}}},
+	{Method: http.MethodGet, Path: "/api/v1/availability/range", Tag: "availability", Summary: "List open slots per day across a date range",
+		Query:    availabilityRangeQuery,
+		Response: openapi.Object{"from": "", "to": "", "time_zone": "", "days": []pkg.DaySlots{}, "next_cursor": ""}},
+	{Method: http.MethodGet, Path: "/api/v1/slots", Tag: "slots", Summary: "List open stored time slots for a date",
+		Query: []openapi.Parameter{dateQuery, {Name: "interviewer_id", Type: "integer"}}, Response: openapi.Object{"slots": []pkg.TimeSlot{}}},
+	{Method: http.MethodPost, Path: "/api/v1/slots", Tag: "slots", Summary: "Create a stored time slot", Roles: staff,
+		Query:   []openapi.Parameter{mergeQuery},
+		Request: handler.TimeSlotRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.TimeSlot{}}},
+	{Method: http.MethodGet, Path: "/api/v1/slots/:id", Tag: "slots", Summary: "Get a stored time slot; its ETag is the version for If-Match",
+		Response: openapi.Object{"slot": pkg.TimeSlot{}}},
+	{Method: http.MethodPatch, Path: "/api/v1/slots/:id", Tag: "slots", Summary: "Move an open stored time slot, if it still matches If-Match", Roles: staff,
+		Query:   []openapi.Parameter{mergeQuery},
+		Request: handler.TimeSlotUpdateRequest{}, Response: openapi.Object{"message": "", "data": pkg.TimeSlot{}}},
+	{Method: http.MethodPost, Path: "/api/v1/slots/import", Tag: "slots", Summary: "Validate and optionally create many time slots from CSV or JSON", Roles: staff,
+		Query:   []openapi.Parameter{{Name: "commit", Type: "boolean", Description: "store the slots if every row is valid; otherwise a dry run"}},
+		Request: []openapi.Object{{"interviewer_id": uint(0), "start_time": "", "end_time": ""}}, Response: pkg.SlotImportReport{}},
+
+	// Bookings and events
+	{Method: http.MethodGet, Path: "/api/v1/bookings", Tag: "bookings", Summary: "List bookings with their intake answers; interviewers see their own", Roles: staff,
+		Response: openapi.Object{"bookings": []pkg.Booking{}}},
+	{Method: http.MethodPost, Path: "/api/v1/bookings", Tag: "bookings", Summary: "Book a stored time slot by slot_id, or an interval of a weekly schedule",
+		Request: routes.BookingRequest{}, Response: openapi.Object{"message": "", "booking": pkg.Booking{}}},
+	{Method: http.MethodGet, Path: "/api/v1/bookings/export", Tag: "bookings", Summary: "Stream bookings as CSV, NDJSON or ICS; interviewers export their own", Roles: staff,
+		Query: []openapi.Parameter{
+			{Name: "format", Description: "csv (default), ndjson or ics"},
+			{Name: "from", Description: "RFC 3339 or YYYY-MM-DD; on start_time"},
+			{Name: "to", Description: "RFC 3339 or YYYY-MM-DD; on start_time, exclusive"},
+			{Name: "interviewer_id", Type: "integer"}, {Name: "status"},
+		},
+		Produces: []string{"text/csv", "application/x-ndjson", "text/calendar"}},
+	{Method: http.MethodGet, Path: "/api/v1/bookings/:id", Tag: "bookings", Summary: "Get a booking with its intake answers", Roles: staff,
+		Response: openapi.Object{"booking": pkg.Booking{}}},
+	{Method: http.MethodDelete, Path: "/api/v1/bookings/:id", Tag: "bookings", Summary: "Cancel a booking", Roles: staff, Response: messageOnly},
+	{Method: http.MethodGet, Path: "/api/v1/events", Tag: "events", Summary: "List calendar events; interviewers see their own", Roles: staff,
+		Query: []openapi.Parameter{
+			{Name: "booking_id", Type: "integer"}, {Name: "interviewer_id", Type: "integer"},
+			{Name: "candidate", Description: "part of booked_by, case-insensitive"},
+			{Name: "status", Description: "scheduled, cancelled, completed or no_show"},
+			{Name: "from", Description: "RFC 3339 or YYYY-MM-DD; on start_time"},
+			{Name: "to", Description: "RFC 3339 or YYYY-MM-DD; on start_time, exclusive"},
+			{Name: "limit", Type: "integer"}, {Name: "offset", Type: "integer"},
+		},
+		Response: openapi.Object{"events": []pkg.Event{}, "total": 0, "limit": 0, "offset": 0}},
+	{Method: http.MethodPost, Path: "/api/v1/events", Tag: "events", Summary: "Create a calendar event", Roles: staff,
+		Request: handler.EventRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.Event{}}},
+	{Method: http.MethodGet, Path: "/api/v1/events/:id", Tag: "events", Summary: "Get a calendar event; its ETag is the version for If-Match", Roles: staff,
+		Response: openapi.Object{"event": pkg.Event{}}},
+	{Method: http.MethodPatch, Path: "/api/v1/events/:id", Tag: "events", Summary: "Change some fields of a calendar event, if it still matches If-Match", Roles: staff,
+		Request: handler.EventUpdateRequest{}, Response: openapi.Object{"message": "", "data": pkg.Event{}}},
+	{Method: http.MethodDelete, Path: "/api/v1/events/:id", Tag: "events", Summary: "Delete a calendar event, if it still matches If-Match; it stays in the database as soft-deleted", Roles: staff, Response: messageOnly},
+
+	// Reporting
+	{Method: http.MethodGet, Path: "/api/v1/analytics", Tag: "analytics", Summary: "Utilization, lead time, cancellation and no-show rates and busiest hours; interviewers see their own", Roles: staff,
+		Query: append(fromToTimeQuery,
+			openapi.Parameter{Name: "interviewer_id", Type: "integer"},
+			openapi.Parameter{Name: "group_by", Description: "comma-separated: interviewer (default), event_type, week"},
+			openapi.Parameter{Name: "tz", Description: "IANA time zone for weeks and hours of day; live only"},
+			openapi.Parameter{Name: "source", Description: "live (default) or rollups, which covers whole UTC weeks"},
+		),
+		Response: pkg.AnalyticsReport{}},
+
+	// Self-service links
+	{Method: http.MethodGet, Path: "/api/v1/manage/:token", Tag: "manage", Summary: "Get the booking of a manage link",
+		Response: openapi.Object{"booking": pkg.Booking{}, "slot": handler.Availability{}}},
+	{Method: http.MethodPost, Path: "/api/v1/manage/:token/cancel", Tag: "manage", Summary: "Cancel the booking of a manage link", Response: messageOnly},
+	{Method: http.MethodPost, Path: "/api/v1/manage/:token/reschedule", Tag: "manage", Summary: "Move the booking of a manage link",
+		Request: handler.RescheduleRequest{}, Response: openapi.Object{"message": "", "booking": nil, "slot": handler.Availability{}}},
+	{Method: http.MethodPost, Path: "/api/v1/bookings/verify/:token", Tag: "manage", Summary: "Confirm a pending_verification booking from its emailed link",
+		Response: openapi.Object{"message": "", "booking": pkg.Booking{}}},
+	{Method: http.MethodPost, Path: "/api/v1/waitlist", Tag: "waitlist", Summary: "Join the waitlist for a date range",
+		Request: handler.WaitlistRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.WaitlistEntry{}, "leave_token": ""}},
+	{Method: http.MethodDelete, Path: "/api/v1/waitlist/:id", Tag: "waitlist", Summary: "Leave the waitlist; staff may remove any entry",
+		Query: []openapi.Parameter{{Name: "token", Description: "leave_token returned when joining"}}, Response: messageOnly},
+	{Method: http.MethodGet, Path: "/api/v1/waitlist/offers/:token", Tag: "waitlist", Summary: "Get a pending waitlist offer",
+		Response: openapi.Object{"offer": pkg.WaitlistOffer{}, "name": ""}},
+	{Method: http.MethodPost, Path: "/api/v1/waitlist/offers/:token/claim", Tag: "waitlist", Summary: "Book the slot of a waitlist offer",
+		Response: openapi.Object{"message": "", "booking": nil}},
+
+	// Event types and intake questions
+	{Method: http.MethodGet, Path: "/api/v1/event-types", Tag: "event types", Summary: "List event types",
+		Response: openapi.Object{"event_types": []pkg.EventType{}}},
+	{Method: http.MethodPost, Path: "/api/v1/event-types", Tag: "event types", Summary: "Create an event type", Roles: staff,
+		Request: handler.EventTypeRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.EventType{}}},
+	{Method: http.MethodGet, Path: "/api/v1/event-types/:id/questions", Tag: "event types", Summary: "List an event type's intake questions",
+		Response: openapi.Object{"questions": []pkg.IntakeQuestion{}}},
+	{Method: http.MethodPost, Path: "/api/v1/event-types/:id/questions", Tag: "event types", Summary: "Add an intake question to an event type", Roles: staff,
+		Request: handler.IntakeQuestionRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.IntakeQuestion{}}},
+	{Method: http.MethodDelete, Path: "/api/v1/questions/:id", Tag: "event types", Summary: "Delete an intake question", Roles: staff, Response: messageOnly},
+
+	// Administration
+	{Method: http.MethodGet, Path: "/api/v1/organizations", Tag: "admin", Summary: "List organizations (platform admins)", Roles: admin,
+		Response: openapi.Object{"organizations": []pkg.Organization{}}},
+	{Method: http.MethodPost, Path: "/api/v1/organizations", Tag: "admin", Summary: "Create an organization (platform admins)", Roles: admin,
+		Request: handler.OrganizationRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.Organization{}}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/audit-logs", Tag: "admin", Summary: "Query the audit log of scheduling changes", Roles: admin,
+		Query: auditLogQuery, Response: openapi.Object{"audit_logs": []pkg.AuditLog{}, "total": 0, "limit": 0, "offset": 0}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/legacy-usage", Tag: "admin", Summary: "Count calls to deprecated routes since startup", Roles: admin,
+		Response: openapi.Object{"routes": []handler.LegacyRouteUsage{}, "deprecated_at": "", "sunset": ""}},
+	{Method: http.MethodPost, Path: "/api/v1/admin/analytics/rollups", Tag: "admin", Summary: "Recompute the weekly analytics rollups of a period", Roles: admin,
+		Query: fromToTimeQuery, Response: openapi.Object{"message": "", "rollups": 0}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/overlaps", Tag: "admin", Summary: "List overlapping time slots and availability windows of the same interviewer", Roles: admin,
+		Query:    []openapi.Parameter{{Name: "kind", Description: "time_slot or availability"}, {Name: "interviewer_id", Type: "integer"}},
+		Response: openapi.Object{"conflicts": []pkg.OverlapConflict{}, "total": 0}},
+}
+
+// legacyOperations are the unversioned routes, kept as adapters of /api/v1
+var legacyOperations = []openapi.Operation{
+	// Availability
+	{Method: http.MethodPost, Path: "/api/interviewer/availability", Tag: "availability", Summary: "Set an interviewer's availability window", Roles: staff,
+		Query:   []openapi.Parameter{mergeQuery},
+		Request: handler.Availability{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": handler.Availability{}}},
+	{Method: http.MethodGet, Path: "/api/availability/:interviewer_id", Tag: "availability", Summary: "Get an interviewer's availability for a date, after holidays and overrides",
+		Query: []openapi.Parameter{dateQuery}, Response: openapi.Object{"availability": []handler.Availability{}, "blocked": false, "reason": ""}},
+	{Method: http.MethodGet, Path: "/api/availability/:interviewer_id/:date", Tag: "availability", Summary: "List an interviewer's legacy availability rows for a date",
+		Response: []openapi.Object{legacyDayEntry}},
+	{Method: http.MethodGet, Path: "/api/availability", Tag: "availability", Summary: "List every interviewer's legacy availability for a date",
+		Query: []openapi.Parameter{dateQuery}, Response: []openapi.Object{legacyDayEntry}},
+	{Method: http.MethodGet, Path: "/api/availability/range", Tag: "availability", Summary: "List open slots per day across a date range",
+		Query:    availabilityRangeQuery,
+		Response: openapi.Object{"from": "", "to": "", "time_zone": "", "days": []pkg.DaySlots{}, "next_cursor": ""}},
+	{Method: http.MethodGet, Path: "/api/interviewer/:interviewer_id/overrides", Tag: "availability", Summary: "List an interviewer's date overrides",
+		Query: fromToQuery, Response: openapi.Object{"overrides": []pkg.AvailabilityOverride{}}},
+	{Method: http.MethodPost, Path: "/api/interviewer/:interviewer_id/overrides", Tag: "availability", Summary: "Set replacement hours for a date or block it", Roles: staff,
+		Request: handler.OverrideRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.AvailabilityOverride{}}},
+	{Method: http.MethodDelete, Path: "/api/overrides/:id", Tag: "availability", Summary: "Delete a date override", Roles: staff, Response: messageOnly},
+	{Method: http.MethodGet, Path: "/api/holidays", Tag: "availability", Summary: "List holidays",
+		Query: fromToQuery, Response: openapi.Object{"holidays": []pkg.Holiday{}}},
+	{Method: http.MethodPost, Path: "/api/holidays", Tag: "availability", Summary: "Import a holiday calendar from CSV (date,name) or ICS", Roles: admin,
+		Form: openapi.Object{"file": openapi.File{}, "calendar": ""}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "calendar": "", "count": 0}},
+	{Method: http.MethodGet, Path: "/api/interviewer/:interviewer_id/schedule", Tag: "availability", Summary: "List an interviewer's weekly schedule",
+		Response: openapi.Object{"templates": []pkg.ScheduleTemplate{}}},
+	{Method: http.MethodPost, Path: "/api/interviewer/:interviewer_id/schedule", Tag: "availability", Summary: "Add a weekly schedule window", Roles: staff,
+		Request: handler.ScheduleTemplateRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.ScheduleTemplate{}}},
+	{Method: http.MethodDelete, Path: "/api/schedule/:id", Tag: "availability", Summary: "Delete a weekly schedule window", Roles: staff, Response: messageOnly},
+	{Method: http.MethodGet, Path: "/availability", Tag: "availability", Summary: "List open slots generated from weekly schedules",
+		Query:    []openapi.Parameter{dateQuery, {Name: "interviewer_id", Type: "integer"}},
+		Response: openapi.Object{"date": "", "available_slots": []pkg.AvailableSlot{}, "total_available": 0}},
+
+	// Bookings
+	{Method: http.MethodPost, Path: "/api/book-slot", Tag: "bookings", Summary: "Book an interval of an interviewer's weekly schedule by date and wall-clock time",
+		Request: bookSlotRequest{}, Response: openapi.Object{"message": "", "booking_id": uint(0), "status": "", "answers": []pkg.BookingAnswer{}}},
+	{Method: http.MethodPost, Path: "/bookings", Tag: "bookings", Summary: "Book an interval of an interviewer's weekly schedule",
+		Request: routes.BookingRequest{}, Response: openapi.Object{"message": "", "booking": pkg.Booking{}}},
+	{Method: http.MethodGet, Path: "/bookings", Tag: "bookings", Summary: "List bookings with their intake answers", Roles: staff,
+		Response: openapi.Object{"bookings": []pkg.Booking{}}},
+	{Method: http.MethodGet, Path: "/api/bookings/:id", Tag: "bookings", Summary: "Get a booking with its intake answers", Roles: staff,
+		Response: openapi.Object{"booking": pkg.Booking{}}},
+	{Method: http.MethodDelete, Path: "/api/bookings/:id", Tag: "bookings", Summary: "Cancel a booking", Roles: staff, Response: messageOnly},
+
+	// Self-service links
+	{Method: http.MethodGet, Path: "/api/manage/:token", Tag: "manage", Summary: "Get the booking of a manage link",
+		Response: openapi.Object{"booking": pkg.Booking{}, "slot": handler.Availability{}}},
+	{Method: http.MethodPost, Path: "/api/manage/:token/cancel", Tag: "manage", Summary: "Cancel the booking of a manage link", Response: messageOnly},
+	{Method: http.MethodPost, Path: "/api/manage/:token/reschedule", Tag: "manage", Summary: "Move the booking of a manage link",
+		Request: handler.RescheduleRequest{}, Response: openapi.Object{"message": "", "booking": nil, "slot": handler.Availability{}}},
+
+	// Waitlist
+	{Method: http.MethodPost, Path: "/api/waitlist", Tag: "waitlist", Summary: "Join the waitlist for a date range",
+		Request: handler.WaitlistRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.WaitlistEntry{}, "leave_token": ""}},
+	{Method: http.MethodDelete, Path: "/api/waitlist/:id", Tag: "waitlist", Summary: "Leave the waitlist; staff may remove any entry",
+		Query: []openapi.Parameter{{Name: "token", Description: "leave_token returned when joining"}}, Response: messageOnly},
+	{Method: http.MethodGet, Path: "/api/waitlist/offers/:token", Tag: "waitlist", Summary: "Get a pending waitlist offer",
+		Response: openapi.Object{"offer": pkg.WaitlistOffer{}, "name": ""}},
+	{Method: http.MethodPost, Path: "/api/waitlist/offers/:token/claim", Tag: "waitlist", Summary: "Book the slot of a waitlist offer",
+		Response: openapi.Object{"message": "", "booking": nil}},
+
+	// Event types and intake questions
+	{Method: http.MethodGet, Path: "/api/event-types", Tag: "event types", Summary: "List event types",
+		Response: openapi.Object{"event_types": []pkg.EventType{}}},
+	{Method: http.MethodPost, Path: "/api/event-types", Tag: "event types", Summary: "Create an event type", Roles: staff,
+		Request: handler.EventTypeRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.EventType{}}},
+	{Method: http.MethodGet, Path: "/api/event-types/:id/questions", Tag: "event types", Summary: "List an event type's intake questions",
+		Response: openapi.Object{"questions": []pkg.IntakeQuestion{}}},
+	{Method: http.MethodPost, Path: "/api/event-types/:id/questions", Tag: "event types", Summary: "Add an intake question to an event type", Roles: staff,
+		Request: handler.IntakeQuestionRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.IntakeQuestion{}}},
+	{Method: http.MethodDelete, Path: "/api/questions/:id", Tag: "event types", Summary: "Delete an intake question", Roles: staff, Response: messageOnly},
+
+	// Interviewers and organizations
+	{Method: http.MethodGet, Path: "/api/interviewers", Tag: "interviewers", Summary: "List interviewers",
+		Response: openapi.Object{"interviewers": []pkg.Interviewer{}}},
+	{Method: http.MethodPost, Path: "/api/interviewers", Tag: "interviewers", Summary: "Create an interviewer", Roles: staff,
+		Request: handler.InterviewerRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.Interviewer{}}},
+	{Method: http.MethodGet, Path: "/api/organizations", Tag: "admin", Summary: "List organizations (platform admins)", Roles: admin,
+		Response: openapi.Object{"organizations": []pkg.Organization{}}},
+	{Method: http.MethodPost, Path: "/api/organizations", Tag: "admin", Summary: "Create an organization (platform admins)", Roles: admin,
+		Request: handler.OrganizationRequest{}, Status: http.StatusCreated, Response: openapi.Object{"message": "", "data": pkg.Organization{}}},
+	{Method: http.MethodGet, Path: "/api/admin/audit-logs", Tag: "admin", Summary: "Query the audit log of scheduling changes", Roles: admin,
+		Query: auditLogQuery, Response: openapi.Object{"audit_logs": []pkg.AuditLog{}, "total": 0, "limit": 0, "offset": 0}},
+}
+
+var metaOperations = []openapi.Operation{
+	{Method: http.MethodGet, Path: "/api/openapi.json", Tag: "meta", Summary: "This document", Response: openapi.Object{}},
+	{Method: http.MethodGet, Path: "/healthz", Tag: "meta", Summary: "Liveness probe; does not touch the database", Response: openapi.Object{"status": ""}},
+	{Method: http.MethodGet, Path: "/readyz", Tag: "meta", Summary: "Readiness probe: database ping and migration state; 503 when not ready",
+		Response: openapi.Object{"status": "", "checks": openapi.Object{"database": "", "migrations": ""}}},
+	{Method: http.MethodGet, Path: "/metrics", Tag: "meta", Summary: "Prometheus metrics", Produces: []string{"text/plain"}},
+}
+
+// deprecated marks operations as deprecated
+func deprecated(operations []openapi.Operation) []openapi.Operation {
+	for i := range operations {
+		operations[i].Deprecated = true
+	}
+	return operations
+}
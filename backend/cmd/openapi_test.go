@@ -0,0 +1,21 @@
+package main
+
+import (
+	"BookingTimeSlot/backend/config"
+	"BookingTimeSlot/backend/pkg"
+	"testing"
+)
+
+func TestSpecDocumentsRoutes(t *testing.T) {
+	cfg := config.Default()
+	if err := apiSpec.CheckRoutes(setUpRouter(&cfg).Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrationCreatesModels(t *testing.T) {
+	newTestServer(t)
+	if err := pkg.CheckMigrations(db, migratedModels()...); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"BookingTimeSlot/backend/config"
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+// newServer serves handler on the configured port with the configured timeouts
+func newServer(cfg config.HTTP, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Address(),
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+}
+
+// shutdownServer stops accepting connections and waits until in-flight requests,
+// such as a booking transaction, have finished or ctx expires
+func shutdownServer(ctx context.Context, server *http.Server) {
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Error("Requests still running at shutdown deadline", "error", err)
+		server.Close()
+		return
+	}
+	slog.Info("In-flight requests drained")
+}
+
+// stopWorkers cancels the background workers and waits until they have finished
+// their current job or ctx expires
+func stopWorkers(ctx context.Context, cancel context.CancelFunc, workers *sync.WaitGroup) {
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		slog.Info("Background workers stopped")
+	case <-ctx.Done():
+		slog.Error("Background workers still running at shutdown deadline")
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"BookingTimeSlot/backend/config"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestShutdownFinishesInFlightRequest(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release // e.g. a booking transaction still running
+		io.WriteString(w, "booked")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Default().HTTP
+	server := newServer(cfg, handler)
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		response, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		responses <- result{string(body), err}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stopped := make(chan struct{})
+	go func() {
+		shutdownServer(ctx, server)
+		close(stopped)
+	}()
+
+	// Shutdown waits for the request instead of cutting it off
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("Serve returned %v, want ErrServerClosed", err)
+	}
+	select {
+	case <-stopped:
+		t.Fatal("shutdown returned while a request was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	got := <-responses
+	if got.err != nil || got.body != "booked" {
+		t.Fatalf("in-flight request got %q, %v; want booked", got.body, got.err)
+	}
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		t.Fatal("shutdown did not return after the request finished")
+	}
+}
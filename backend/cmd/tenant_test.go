@@ -0,0 +1,258 @@
+package main
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/pkg"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrganizationsCannotReachEachOther(t *testing.T) {
+	s := newTestServer(t)
+	acme, beta := s.organization("acme"), s.organization("beta")
+	acmeAdmin, betaAdmin := s.token(auth.RoleAdmin, acme, 0), s.token(auth.RoleAdmin, beta, 0)
+
+	var interviewer struct{ Data pkg.Interviewer }
+	s.expect(s.do(http.MethodPost, "/api/v1/interviewers", acme, acmeAdmin, map[string]interface{}{
+		"name": "Grace", "email": "grace@acme.test",
+	}), http.StatusCreated, &interviewer)
+	slot := futureSlot(t, acme, interviewer.Data.ID, 2)
+	var created struct{ Booking pkg.Booking }
+	s.expect(s.do(http.MethodPost, "/api/v1/bookings", acme, "", map[string]interface{}{
+		"slot_id": slot.ID, "name": "Ada", "email": "ada@example.com",
+	}), http.StatusOK, &created)
+	var event pkg.Event
+	if err := pkg.ForOrganization(db, acme.ID).Where("booking_id = ?", created.Booking.ID).First(&event).Error; err != nil {
+		t.Fatalf("booking has no event: %v", err)
+	}
+	template, err := pkg.SaveScheduleTemplate(pkg.ForOrganization(db, acme.ID), interviewer.Data.ID, 1, "09:00", "10:00", 60, "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var listed struct{ Interviewers []pkg.Interviewer }
+	s.expect(s.do(http.MethodGet, "/api/v1/interviewers", beta, betaAdmin, nil), http.StatusOK, &listed)
+	if len(listed.Interviewers) != 0 {
+		t.Errorf("beta lists acme's interviewers: %+v", listed.Interviewers)
+	}
+
+	move := map[string]interface{}{"start_time": slot.StartTime.Add(time.Hour), "end_time": slot.EndTime.Add(time.Hour)}
+	for _, request := range []struct {
+		method, path string
+		body         interface{}
+	}{
+		{http.MethodGet, fmt.Sprintf("/api/v1/slots/%d", slot.ID), nil},
+		{http.MethodPatch, fmt.Sprintf("/api/v1/slots/%d", slot.ID), move},
+		{http.MethodGet, fmt.Sprintf("/api/v1/bookings/%d", created.Booking.ID), nil},
+		{http.MethodDelete, fmt.Sprintf("/api/v1/bookings/%d", created.Booking.ID), nil},
+		{http.MethodGet, fmt.Sprintf("/api/v1/events/%d", event.ID), nil},
+		{http.MethodPatch, fmt.Sprintf("/api/v1/events/%d", event.ID), map[string]interface{}{"title": "Taken over"}},
+		{http.MethodDelete, fmt.Sprintf("/api/v1/events/%d", event.ID), nil},
+		{http.MethodDelete, fmt.Sprintf("/api/v1/schedules/%d", template.ID), nil},
+	} {
+		response := s.do(request.method, request.path, beta, betaAdmin, request.body)
+		if response.Code != http.StatusNotFound {
+			t.Errorf("beta %s %s: got status %d, want 404: %s", request.method, request.path, response.Code, response.Body)
+		}
+	}
+
+	// A token of one organization is refused on the other's host
+	s.expect(s.do(http.MethodGet, fmt.Sprintf("/api/v1/bookings/%d", created.Booking.ID), beta, acmeAdmin, nil), http.StatusForbidden, nil)
+
+	scoped := pkg.ForOrganization(db, acme.ID)
+	var booking pkg.Booking
+	if err := scoped.First(&booking, created.Booking.ID).Error; err != nil || booking.Status != "booked" {
+		t.Errorf("acme's booking changed: status %q (err %v)", booking.Status, err)
+	}
+	if err := scoped.First(slot, slot.ID).Error; err != nil || !slot.IsBooked {
+		t.Errorf("acme's slot changed (err %v)", err)
+	}
+	if err := scoped.First(&event, event.ID).Error; err != nil || event.Title == "Taken over" {
+		t.Errorf("acme's event changed: %q (err %v)", event.Title, err)
+	}
+	if err := scoped.First(&pkg.ScheduleTemplate{}, template.ID).Error; err != nil {
+		t.Errorf("acme's schedule is gone: %v", err)
+	}
+}
+
+func TestHolidaysBelongToOneOrganization(t *testing.T) {
+	s := newTestServer(t)
+	acme, beta := s.organization("acme"), s.organization("beta")
+	day := time.Now().UTC().AddDate(0, 0, 2).Format("2006-01-02")
+	if _, err := pkg.SaveHolidays(pkg.ForOrganization(db, acme.ID), "company", []pkg.Holiday{{Date: day, Name: "Founders Day"}}); err != nil {
+		t.Fatal(err)
+	}
+	// The same date may be a holiday of another organization's calendar too
+	if _, err := pkg.SaveHolidays(pkg.ForOrganization(db, beta.ID), "company", []pkg.Holiday{{Date: day, Name: "Offsite"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var listed struct{ Holidays []pkg.Holiday }
+	s.expect(s.do(http.MethodGet, "/api/v1/holidays", acme, "", nil), http.StatusOK, &listed)
+	if len(listed.Holidays) != 1 || listed.Holidays[0].Name != "Founders Day" {
+		t.Errorf("acme holidays = %+v, want only Founders Day", listed.Holidays)
+	}
+
+	other := s.organization("other")
+	s.expect(s.do(http.MethodGet, "/api/v1/holidays", other, "", nil), http.StatusOK, &listed)
+	if len(listed.Holidays) != 0 {
+		t.Errorf("other organization sees holidays %+v", listed.Holidays)
+	}
+}
+
+func TestStaffOnlyWrites(t *testing.T) {
+	s := newTestServer(t)
+	acme := s.organization("acme")
+
+	for _, request := range []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/interviewers"},
+		{http.MethodPost, "/api/v1/interviewers/1/overrides"},
+		{http.MethodDelete, "/api/v1/overrides/1"},
+		{http.MethodPost, "/api/v1/interviewers/1/schedules"},
+		{http.MethodDelete, "/api/v1/schedules/1"},
+		{http.MethodPost, "/api/v1/holidays"},
+		{http.MethodPost, "/api/v1/availability"},
+		{http.MethodPost, "/api/v1/slots"},
+		{http.MethodPost, "/api/v1/event-types"},
+		{http.MethodPost, "/api/v1/event-types/1/questions"},
+		{http.MethodDelete, "/api/v1/questions/1"},
+		{http.MethodPost, "/api/interviewer/availability"},
+		{http.MethodPost, "/api/interviewer/1/overrides"},
+		{http.MethodDelete, "/api/overrides/1"},
+		{http.MethodPost, "/api/interviewer/1/schedule"},
+		{http.MethodDelete, "/api/schedule/1"},
+		{http.MethodPost, "/api/event-types"},
+		{http.MethodPost, "/api/event-types/1/questions"},
+		{http.MethodDelete, "/api/questions/1"},
+		{http.MethodPost, "/api/interviewers"},
+		{http.MethodPost, "/api/holidays"},
+		{http.MethodGet, "/bookings"},
+	} {
+		if response := s.do(request.method, request.path, acme, "", nil); response.Code != http.StatusUnauthorized {
+			t.Errorf("anonymous %s %s: got status %d, want 401", request.method, request.path, response.Code)
+		}
+	}
+
+	// Holidays apply to the whole organization, so only admins import them
+	interviewer := s.token(auth.RoleInterviewer, acme, 1)
+	s.expect(s.do(http.MethodPost, "/api/v1/holidays", acme, interviewer, nil), http.StatusForbidden, nil)
+}
+
+func TestOverridesOfOrganizationsDoNotReplaceEachOther(t *testing.T) {
+	s := newTestServer(t)
+	acme, beta := s.organization("acme"), s.organization("beta")
+	day := time.Now().UTC().AddDate(0, 0, 2)
+	date := day.Format("2006-01-02")
+	at := func(hour int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, time.UTC)
+	}
+
+	// Interviewer IDs of migrated data may coincide across organizations
+	if _, err := pkg.SetAvailabilityOverride(pkg.ForOrganization(db, acme.ID), 7, date, false, at(9), at(12), "acme hours"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pkg.SetAvailabilityOverride(pkg.ForOrganization(db, beta.ID), 7, date, true, time.Time{}, time.Time{}, "beta offsite"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, organization := range []*pkg.Organization{acme, beta} {
+		var listed struct{ Overrides []pkg.AvailabilityOverride }
+		s.expect(s.do(http.MethodGet, "/api/v1/interviewers/7/overrides", organization, "", nil), http.StatusOK, &listed)
+		if len(listed.Overrides) != 1 || listed.Overrides[0].OrganizationID != organization.ID {
+			t.Fatalf("%s overrides = %+v, want only its own", organization.Slug, listed.Overrides)
+		}
+		if want := organization.Slug + " "; !strings.HasPrefix(listed.Overrides[0].Reason, want) {
+			t.Errorf("%s override reason = %q, replaced by another organization", organization.Slug, listed.Overrides[0].Reason)
+		}
+	}
+}
+
+func TestOverlapsOfOrganizationsAreTheirOwn(t *testing.T) {
+	s := newTestServer(t)
+	acme, beta := s.organization("acme"), s.organization("beta")
+	start := time.Now().UTC().AddDate(0, 0, 2).Truncate(time.Hour)
+	if _, err := pkg.CreateTimeSlot(pkg.ForOrganization(db, acme.ID), 7, start, start.Add(time.Hour), false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Jobs that serve every organization see the slots of all of them
+	all := pkg.AllOrganizations(db)
+	if err := pkg.CheckTimeSlotOverlap(all, beta.ID, 7, start, start.Add(time.Hour), 0); err != nil {
+		t.Errorf("acme's slot overlaps beta's interviewer: %v", err)
+	}
+	if err := pkg.CheckTimeSlotOverlap(all, acme.ID, 7, start, start.Add(time.Hour), 0); !errors.Is(err, pkg.ErrConflict) {
+		t.Errorf("acme's slot does not overlap acme's interviewer: %v", err)
+	}
+
+	if _, err := pkg.CreateTimeSlot(pkg.ForOrganization(db, beta.ID), 7, start, start.Add(time.Hour), false); err != nil {
+		t.Fatal(err)
+	}
+	conflicts, err := pkg.TimeSlotConflicts(all, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("slots of different organizations conflict: %+v", conflicts)
+	}
+}
+
+func TestInterviewersWriteOnlyTheirOwnHours(t *testing.T) {
+	s := newTestServer(t)
+	acme, beta := s.organization("acme"), s.organization("beta")
+	scoped := pkg.ForOrganization(db, acme.ID)
+	grace, err := pkg.CreateInterviewer(scoped, "Grace", "grace@acme.test", "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alan, err := pkg.CreateInterviewer(scoped, "Alan", "alan@acme.test", "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outsider, err := pkg.CreateInterviewer(pkg.ForOrganization(db, beta.ID), "Barbara", "barbara@beta.test", "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, admin := s.token(auth.RoleInterviewer, acme, grace.ID), s.token(auth.RoleAdmin, acme, 0)
+
+	start := time.Now().UTC().AddDate(0, 0, 2).Truncate(time.Hour)
+	date := start.Format("2006-01-02")
+	type write struct {
+		path string
+		body map[string]interface{}
+	}
+	writes := func(interviewerID uint) []write {
+		return []write{
+			{"/api/v1/slots", map[string]interface{}{"interviewer_id": interviewerID, "start_time": start, "end_time": start.Add(time.Hour)}},
+			{"/api/v1/availability", map[string]interface{}{"interviewer_id": interviewerID, "start_time": start, "end_time": start.Add(time.Hour), "working_days": []int{1}}},
+			{fmt.Sprintf("/api/v1/interviewers/%d/overrides", interviewerID), map[string]interface{}{"date": date, "unavailable": true}},
+			{fmt.Sprintf("/api/v1/interviewers/%d/schedules", interviewerID), map[string]interface{}{"weekday": 1, "start_time": "09:00", "end_time": "12:00", "step_minutes": 60}},
+		}
+	}
+	for _, write := range writes(alan.ID) {
+		s.expect(s.do(http.MethodPost, write.path, acme, token, write.body), http.StatusForbidden, nil)
+	}
+	// Interviewers of other organizations, or of none, are not found
+	for _, interviewerID := range []uint{outsider.ID, 99} {
+		for _, write := range writes(interviewerID) {
+			s.expect(s.do(http.MethodPost, write.path, acme, admin, write.body), http.StatusNotFound, nil)
+		}
+	}
+	own := writes(grace.ID)[0]
+	s.expect(s.do(http.MethodPost, own.path, acme, token, own.body), http.StatusCreated, nil)
+
+	override, err := pkg.SetAvailabilityOverride(scoped, alan.ID, date, true, time.Time{}, time.Time{}, "offsite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	template, err := pkg.SaveScheduleTemplate(scoped, alan.ID, 1, "09:00", "12:00", 60, "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.expect(s.do(http.MethodDelete, fmt.Sprintf("/api/v1/overrides/%d", override.ID), acme, token, nil), http.StatusForbidden, nil)
+	s.expect(s.do(http.MethodDelete, fmt.Sprintf("/api/v1/schedules/%d", template.ID), acme, token, nil), http.StatusForbidden, nil)
+	s.expect(s.do(http.MethodDelete, fmt.Sprintf("/api/v1/schedules/%d", template.ID), acme, admin, nil), http.StatusOK, nil)
+}
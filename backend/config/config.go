@@ -1,86 +1,233 @@
+// Package config loads the server's settings into one typed struct. Settings
+// come from, in increasing precedence: built-in defaults, a YAML or TOML file,
+// environment variables (optionally from a .env file) and command-line flags.
+// Every setting has a file key, an environment variable and a flag, e.g.
+// database.host, DB_HOST and -db-host; the yaml tags name the keys of both
+// file formats. Secrets have no defaults.
 package config
 
 import (
 	"fmt"
-	"log"
-	"os"
-
-	"gorm.io/driver/postgres" // PostgreSQL driver
-	"gorm.io/gorm"            // GORM ORM for Go
+	"strconv"
+	"strings"
+	"time"
 )
 
-// Config holds the database connection parameters
+// Config holds every setting of the server
 type Config struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	Database string
-}
-
-// GetConfig returns the database configuration
-func GetConfig() *Config {
-	// Fetch database credentials from environment variables (fallback to provided defaults)
-	return &Config{
-		Host:     getEnv("DB_HOST", "localhost"),              // PostgreSQL server address
-		Port:     getEnvAsInt("DB_PORT", 5432),                // PostgreSQL port
-		User:     getEnv("DB_USER", "postgres"),               // PostgreSQL username
-		Password: getEnv("DB_PASSWORD", "jingle_bell_jingle"), // PostgreSQL password
-		Database: getEnv("DB_NAME", "postgres"),               // Database name
-	}
+	Database   Database   `yaml:"database"`
+	HTTP       HTTP       `yaml:"http"`
+	CORS       CORS       `yaml:"cors"`
+	SMTP       SMTP       `yaml:"smtp"`
+	Auth       Auth       `yaml:"auth"`
+	Scheduling Scheduling `yaml:"scheduling"`
+	RateLimit  RateLimit  `yaml:"rate_limit"`
+	Logging    Logging    `yaml:"logging"`
+	Tracing    Tracing    `yaml:"tracing"`
 }
 
-// ConnectDB connects to the PostgreSQL database using the provided config
-func ConnectDB() *gorm.DB {
-	config := GetConfig()
+// Database is the PostgreSQL connection and how its queries are logged
+type Database struct {
+	Host              string `yaml:"host" env:"DB_HOST"`
+	Port              int    `yaml:"port" env:"DB_PORT"`
+	User              string `yaml:"user" env:"DB_USER"`
+	Password          string `yaml:"password" env:"DB_PASSWORD"`
+	Name              string `yaml:"name" env:"DB_NAME"`
+	SSLMode           string `yaml:"sslmode" env:"DB_SSLMODE"`
+	LogLevel          string `yaml:"log_level" env:"DB_LOG_LEVEL"`         // silent, error, warn or info
+	SlowQueryMS       int    `yaml:"slow_query_ms" env:"DB_SLOW_QUERY_MS"` // 0 disables slow query logging
+	OverlapConstraint bool   `yaml:"overlap_constraint" env:"SLOT_OVERLAP_CONSTRAINT"`
+}
 
-	// Format the connection string
-	connStr := fmt.Sprintf(
-		"user=%s password=%s dbname=%s host=%s port=%d sslmode=disable",
-		config.User, config.Password, config.Database, config.Host, config.Port,
-	)
+// HTTP is the API server's listener, timeouts and tenant routing
+type HTTP struct {
+	Port              int           `yaml:"port" env:"PORT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	IdempotencyWindow time.Duration `yaml:"idempotency_window" env:"IDEMPOTENCY_WINDOW"` // how long responses are replayed for an Idempotency-Key
+	RequireIfMatch    bool          `yaml:"require_if_match" env:"REQUIRE_IF_MATCH"`     // reject changes to versioned resources without If-Match
+	TenantBaseDomain  string        `yaml:"tenant_base_domain" env:"TENANT_BASE_DOMAIN"` // subdomains of it select an organization
+	TrustedProxies    []string      `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`       // whose X-Forwarded-For gives the client IP
+}
 
-	// Establish the connection
-	db, err := gorm.Open(postgres.Open(connStr), &gorm.Config{})
-	if err != nil {
-		log.Fatalf("⚠️ Failed to connect to database: %v", err)
-		return nil // This is unnecessary since log.Fatalf will terminate the program
-	}
+// CORS lists who may call the API from a browser
+type CORS struct {
+	AllowedOrigins []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"` // "*" allows every origin
+	AllowedHeaders []string `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS"`
+	ExposedHeaders []string `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS"` // response headers browsers let scripts read, e.g. ETag for If-Match
+}
+
+// SMTP is the mail server notifications are sent through
+type SMTP struct {
+	Host     string `yaml:"host" env:"SMTP_HOST"`
+	Port     int    `yaml:"port" env:"SMTP_PORT"`
+	Username string `yaml:"username" env:"EMAIL"`
+	Password string `yaml:"password" env:"EMAIL_PASSWORD"`
+	From     string `yaml:"from" env:"SMTP_FROM"` // defaults to Username
+}
+
+// Auth signs and verifies API tokens
+type Auth struct {
+	Secret string `yaml:"secret" env:"AUTH_SECRET"`
+}
+
+// Scheduling holds the waitlist, email verification and self-service link defaults
+type Scheduling struct {
+	WaitlistHoldMinutes     int           `yaml:"waitlist_hold_minutes" env:"WAITLIST_HOLD_MINUTES"`
+	WaitlistClaimURL        string        `yaml:"waitlist_claim_url" env:"WAITLIST_CLAIM_URL"`
+	VerifyEmails            bool          `yaml:"verify_booking_emails" env:"VERIFY_BOOKING_EMAILS"` // hold new bookings until the candidate confirms their email
+	VerificationHoldMinutes int           `yaml:"verification_hold_minutes" env:"VERIFICATION_HOLD_MINUTES"`
+	VerifyBookingURL        string        `yaml:"verify_booking_url" env:"VERIFY_BOOKING_URL"`
+	HoldExpiryInterval      time.Duration `yaml:"hold_expiry_interval" env:"HOLD_EXPIRY_INTERVAL"` // how often expired holds are released
+	ManageBookingURL        string        `yaml:"manage_booking_url" env:"MANAGE_BOOKING_URL"`
+	MaxActiveBookings       int           `yaml:"max_active_bookings_per_email" env:"MAX_ACTIVE_BOOKINGS_PER_EMAIL"` // 0 means no cap
+}
+
+// RateLimit caps how often the public booking routes are used per client IP,
+// candidate email and interviewer. Each limit is requests an hour, of which
+// the burst may come at once; 0 requests an hour disables the limit.
+type RateLimit struct {
+	IPPerHour          int `yaml:"ip_per_hour" env:"RATE_LIMIT_IP_PER_HOUR"`
+	IPBurst            int `yaml:"ip_burst" env:"RATE_LIMIT_IP_BURST"`
+	EmailPerHour       int `yaml:"email_per_hour" env:"RATE_LIMIT_EMAIL_PER_HOUR"`
+	EmailBurst         int `yaml:"email_burst" env:"RATE_LIMIT_EMAIL_BURST"`
+	InterviewerPerHour int `yaml:"interviewer_per_hour" env:"RATE_LIMIT_INTERVIEWER_PER_HOUR"`
+	InterviewerBurst   int `yaml:"interviewer_burst" env:"RATE_LIMIT_INTERVIEWER_BURST"`
+}
+
+// Logging is the process logger's level and output format
+type Logging struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`   // debug, info, warn or error
+	Format string `yaml:"format" env:"LOG_FORMAT"` // json or text
+}
+
+// Tracing picks where OpenTelemetry spans go. The OTLP endpoint and headers are
+// read by the exporter from the standard OTEL_EXPORTER_OTLP_* variables.
+type Tracing struct {
+	Exporter    string `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"` // otlp, stdout or none
+	ServiceName string `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+}
 
-	// Test the database connection
-	sqlDB, err := db.DB()
-	if err != nil {
-		log.Fatalf("⚠️ Error retrieving database instance: %v", err)
-		return nil
+// Default returns the settings used where nothing else is given
+func Default() Config {
+	return Config{
+		Database: Database{
+			Host:        "localhost",
+			Port:        5432,
+			SSLMode:     "disable",
+			LogLevel:    "warn",
+			SlowQueryMS: 200,
+		},
+		HTTP: HTTP{
+			Port:              8080,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+			IdempotencyWindow: 24 * time.Hour,
+			RequireIfMatch:    true,
+		},
+		CORS: CORS{
+			AllowedOrigins: []string{"*"},
+			AllowedHeaders: []string{"Origin", "Content-Length", "Content-Type", "Idempotency-Key", "If-Match"},
+			ExposedHeaders: []string{"ETag"},
+		},
+		SMTP: SMTP{
+			Host: "smtp.gmail.com",
+			Port: 587,
+		},
+		Scheduling: Scheduling{
+			WaitlistHoldMinutes:     30,
+			WaitlistClaimURL:        "http://localhost:3000/waitlist/claim",
+			VerificationHoldMinutes: 30,
+			VerifyBookingURL:        "http://localhost:3000/verify",
+			HoldExpiryInterval:      time.Minute,
+			ManageBookingURL:        "http://localhost:3000/manage",
+			MaxActiveBookings:       5,
+		},
+		RateLimit: RateLimit{
+			IPPerHour:          30,
+			IPBurst:            10,
+			EmailPerHour:       10,
+			EmailBurst:         3,
+			InterviewerPerHour: 60,
+			InterviewerBurst:   20,
+		},
+		Logging: Logging{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			ServiceName: "booking-timeslot",
+		},
 	}
+}
+
+// -------------------- Derived Settings --------------------
+
+// DSN is the PostgreSQL connection string
+func (d Database) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		d.Host, d.Port, d.User, d.Password, d.Name, d.SSLMode)
+}
+
+// SlowQueryThreshold is how long a query may take before it is logged as slow
+func (d Database) SlowQueryThreshold() time.Duration {
+	return time.Duration(d.SlowQueryMS) * time.Millisecond
+}
+
+// Address is the host:port the server listens on
+func (h HTTP) Address() string {
+	return ":" + strconv.Itoa(h.Port)
+}
 
-	// Ping the database to ensure it's reachable
-	if err := sqlDB.Ping(); err != nil {
-		log.Fatalf("⚠️ Database connection test failed: %v", err)
-		return nil
+// AllowsAllOrigins reports whether any origin may call the API
+func (c CORS) AllowsAllOrigins() bool {
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			return true
+		}
 	}
+	return false
+}
 
-	log.Println("Database connected successfully!")
-	return db
+// Configured reports whether credentials for sending email are set
+func (s SMTP) Configured() bool {
+	return s.Username != "" && s.Password != ""
 }
 
-// Helper function to get environment variables with a fallback value
-func getEnv(key, fallback string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// Sender is the From address of outgoing email
+func (s SMTP) Sender() string {
+	if s.From != "" {
+		return s.From
 	}
-	return fallback
+	return s.Username
+}
+
+// Address is the mail server's host:port
+func (s SMTP) Address() string {
+	return s.Host + ":" + strconv.Itoa(s.Port)
+}
+
+// WaitlistHold is how long a freed slot is reserved for the offered candidate
+func (s Scheduling) WaitlistHold() time.Duration {
+	return time.Duration(s.WaitlistHoldMinutes) * time.Minute
+}
+
+// VerificationHold is how long an unverified booking holds its slot
+func (s Scheduling) VerificationHold() time.Duration {
+	return time.Duration(s.VerificationHoldMinutes) * time.Minute
 }
 
-// Helper function to get environment variables as integers
-func getEnvAsInt(key string, fallback int) int {
-	if value, exists := os.LookupEnv(key); exists {
-		var intValue int
-		_, err := fmt.Sscanf(value, "%d", &intValue)
-		if err == nil {
-			return intValue
+// oneOf reports whether value, ignoring case, is one of allowed
+func oneOf(value string, allowed ...string) bool {
+	for _, candidate := range allowed {
+		if strings.EqualFold(value, candidate) {
+			return true
 		}
-		log.Printf("⚠️ Invalid value for %s, using fallback: %d", key, fallback)
 	}
-	return fallback
+	return false
 }
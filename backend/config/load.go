@@ -0,0 +1,198 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the settings file when no -config flag is given
+const FileEnv = "CONFIG_FILE"
+
+// Load reads the settings: defaults, then the file named by -config or
+// CONFIG_FILE (.yaml, .yml or .toml), then environment variables, loading a
+// .env file first if there is one, then the flags in args. Pass nil args to
+// skip flags, e.g. from tools with flags of their own. The result is not
+// validated; call Validate or a section's Validate.
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+	settings := settingsOf(&cfg)
+
+	// Flags are parsed first to find the file, but applied last
+	flags := flag.NewFlagSet("server", flag.ContinueOnError)
+	path := flags.String("config", os.Getenv(FileEnv), "YAML or TOML settings file")
+	flagValues := map[string]string{}
+	for _, s := range settings {
+		name := s.flagName()
+		flags.Func(name, s.key+" ("+s.env+")", func(value string) error {
+			flagValues[name] = value
+			return nil
+		})
+	}
+	if args != nil {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+	}
+
+	if *path != "" {
+		if err := loadFile(settings, *path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+	var problems []error
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok {
+			if err := s.set(value); err != nil {
+				problems = append(problems, fmt.Errorf("%s: %w", s.env, err))
+			}
+		}
+	}
+	for _, s := range settings {
+		if value, ok := flagValues[s.flagName()]; ok {
+			if err := s.set(value); err != nil {
+				problems = append(problems, fmt.Errorf("-%s: %w", s.flagName(), err))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	return &cfg, nil
+}
+
+// loadFile overlays the file's settings, parsed like environment variables.
+// Unknown keys are errors so typos surface.
+func loadFile(settings []setting, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	var sections map[string]map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &sections)
+	case ".toml":
+		err = toml.Unmarshal(content, &sections)
+	default:
+		return fmt.Errorf("settings file %s must end in .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid settings file %s: %w", path, err)
+	}
+
+	byKey := make(map[string]setting, len(settings))
+	for _, s := range settings {
+		byKey[s.key] = s
+	}
+	var problems []error
+	for section, values := range sections {
+		for name, value := range values {
+			key := section + "." + name
+			s, ok := byKey[key]
+			if !ok {
+				problems = append(problems, fmt.Errorf("%s: unknown setting %s", path, key))
+				continue
+			}
+			if err := s.set(fileValue(value)); err != nil {
+				problems = append(problems, fmt.Errorf("%s: %s: %w", path, key, err))
+			}
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// fileValue formats a decoded file value as its environment variable would be written
+func fileValue(value interface{}) string {
+	if list, ok := value.([]interface{}); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// -------------------- Settings --------------------
+
+// setting is one leaf field of Config with its file key and environment variable
+type setting struct {
+	key   string // e.g. database.host
+	env   string // e.g. DB_HOST
+	value reflect.Value
+}
+
+// settingsOf lists the leaf fields of cfg that have an env tag
+func settingsOf(cfg *Config) []setting {
+	var settings []setting
+	sections := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		sectionKey := sections.Type().Field(i).Tag.Get("yaml")
+		for j := 0; j < section.NumField(); j++ {
+			field := section.Type().Field(j)
+			if env := field.Tag.Get("env"); env != "" {
+				settings = append(settings, setting{key: sectionKey + "." + field.Tag.Get("yaml"), env: env, value: section.Field(j)})
+			}
+		}
+	}
+	return settings
+}
+
+// flagName derives the flag from the environment variable, e.g. -db-host
+func (s setting) flagName() string {
+	return strings.ReplaceAll(strings.ToLower(s.env), "_", "-")
+}
+
+// set parses a string into the field: durations like "30s", comma-separated lists
+func (s setting) set(value string) error {
+	value = strings.TrimSpace(value)
+	switch target := s.value.Addr().Interface().(type) {
+	case *string:
+		*target = value
+	case *int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		*target = parsed
+	case *bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		*target = parsed
+	case *time.Duration:
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 30s or 2m", value)
+		}
+		*target = parsed
+	case *[]string:
+		*target = nil
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*target = append(*target, item)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Type())
+	}
+	return nil
+}
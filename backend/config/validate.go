@@ -0,0 +1,218 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Validate checks every section and reports all problems at once, naming each
+// setting by its file key and environment variable
+func (c *Config) Validate() error {
+	return errors.Join(
+		c.Database.Validate(),
+		c.HTTP.Validate(),
+		c.CORS.Validate(),
+		c.SMTP.Validate(),
+		c.Auth.Validate(),
+		c.Scheduling.Validate(),
+		c.RateLimit.Validate(),
+		c.Logging.Validate(),
+		c.Tracing.Validate(),
+	)
+}
+
+// Validate requires the connection settings; the password has no default
+func (d Database) Validate() error {
+	var problems []error
+	if d.Host == "" {
+		problems = append(problems, required("database.host", "DB_HOST"))
+	}
+	problems = append(problems, port("database.port", "DB_PORT", d.Port))
+	if d.User == "" {
+		problems = append(problems, required("database.user", "DB_USER"))
+	}
+	if d.Password == "" {
+		problems = append(problems, required("database.password", "DB_PASSWORD"))
+	}
+	if d.Name == "" {
+		problems = append(problems, required("database.name", "DB_NAME"))
+	}
+	if !oneOf(d.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full") {
+		problems = append(problems, invalid("database.sslmode", "DB_SSLMODE", d.SSLMode, "disable, allow, prefer, require, verify-ca or verify-full"))
+	}
+	if !oneOf(d.LogLevel, "silent", "error", "warn", "info") {
+		problems = append(problems, invalid("database.log_level", "DB_LOG_LEVEL", d.LogLevel, "silent, error, warn or info"))
+	}
+	if d.SlowQueryMS < 0 {
+		problems = append(problems, invalid("database.slow_query_ms", "DB_SLOW_QUERY_MS", d.SlowQueryMS, "0 or more"))
+	}
+	return errors.Join(problems...)
+}
+
+// Validate checks the port and that every timeout is set
+func (h HTTP) Validate() error {
+	return errors.Join(
+		port("http.port", "PORT", h.Port),
+		positive("http.read_timeout", "HTTP_READ_TIMEOUT", h.ReadTimeout),
+		positive("http.write_timeout", "HTTP_WRITE_TIMEOUT", h.WriteTimeout),
+		positive("http.idle_timeout", "HTTP_IDLE_TIMEOUT", h.IdleTimeout),
+		positive("http.shutdown_timeout", "SHUTDOWN_TIMEOUT", h.ShutdownTimeout),
+		positive("http.idempotency_window", "IDEMPOTENCY_WINDOW", h.IdempotencyWindow),
+		proxies("http.trusted_proxies", "TRUSTED_PROXIES", h.TrustedProxies),
+	)
+}
+
+// Validate checks each origin is "*" or a scheme and host without a path
+func (c CORS) Validate() error {
+	if len(c.AllowedOrigins) == 0 {
+		return required("cors.allowed_origins", "CORS_ALLOWED_ORIGINS")
+	}
+	var problems []error
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		if parsed, err := url.Parse(origin); err != nil || parsed.Scheme == "" || parsed.Host == "" || parsed.Path != "" {
+			problems = append(problems, invalid("cors.allowed_origins", "CORS_ALLOWED_ORIGINS", origin, `"*" or an origin such as https://app.example.com`))
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// Validate allows SMTP to be left unconfigured, in which case email is logged
+// and dropped, but not half configured
+func (s SMTP) Validate() error {
+	var problems []error
+	if s.Host == "" {
+		problems = append(problems, required("smtp.host", "SMTP_HOST"))
+	}
+	problems = append(problems, port("smtp.port", "SMTP_PORT", s.Port))
+	if s.Username != "" && s.Password == "" {
+		problems = append(problems, fmt.Errorf("smtp.password (EMAIL_PASSWORD) is required when smtp.username (EMAIL) is set"))
+	}
+	if s.Username == "" && s.Password != "" {
+		problems = append(problems, fmt.Errorf("smtp.username (EMAIL) is required when smtp.password (EMAIL_PASSWORD) is set"))
+	}
+	return errors.Join(problems...)
+}
+
+// Validate requires the token secret; there is deliberately no default
+func (a Auth) Validate() error {
+	if a.Secret == "" {
+		return required("auth.secret", "AUTH_SECRET")
+	}
+	return nil
+}
+
+// Validate checks the hold timings and that the emailed links are absolute
+func (s Scheduling) Validate() error {
+	var problems []error
+	if s.WaitlistHoldMinutes <= 0 {
+		problems = append(problems, invalid("scheduling.waitlist_hold_minutes", "WAITLIST_HOLD_MINUTES", s.WaitlistHoldMinutes, "a positive number of minutes"))
+	}
+	if s.VerificationHoldMinutes <= 0 {
+		problems = append(problems, invalid("scheduling.verification_hold_minutes", "VERIFICATION_HOLD_MINUTES", s.VerificationHoldMinutes, "a positive number of minutes"))
+	}
+	if s.MaxActiveBookings < 0 {
+		problems = append(problems, invalid("scheduling.max_active_bookings_per_email", "MAX_ACTIVE_BOOKINGS_PER_EMAIL", s.MaxActiveBookings, "0 or more"))
+	}
+	problems = append(problems,
+		positive("scheduling.hold_expiry_interval", "HOLD_EXPIRY_INTERVAL", s.HoldExpiryInterval),
+		absoluteURL("scheduling.waitlist_claim_url", "WAITLIST_CLAIM_URL", s.WaitlistClaimURL),
+		absoluteURL("scheduling.verify_booking_url", "VERIFY_BOOKING_URL", s.VerifyBookingURL),
+		absoluteURL("scheduling.manage_booking_url", "MANAGE_BOOKING_URL", s.ManageBookingURL),
+	)
+	return errors.Join(problems...)
+}
+
+// Validate checks each enabled limit has a burst of at least one request
+func (r RateLimit) Validate() error {
+	var problems []error
+	for _, limit := range []struct {
+		name           string
+		perHour, burst int
+	}{
+		{"ip", r.IPPerHour, r.IPBurst},
+		{"email", r.EmailPerHour, r.EmailBurst},
+		{"interviewer", r.InterviewerPerHour, r.InterviewerBurst},
+	} {
+		key, env := "rate_limit."+limit.name, "RATE_LIMIT_"+strings.ToUpper(limit.name)
+		if limit.perHour < 0 {
+			problems = append(problems, invalid(key+"_per_hour", env+"_PER_HOUR", limit.perHour, "0 or more"))
+		}
+		if limit.perHour > 0 && limit.burst < 1 {
+			problems = append(problems, invalid(key+"_burst", env+"_BURST", limit.burst, "at least 1 when the limit is enabled"))
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// Validate checks the level and format are ones the logger understands
+func (l Logging) Validate() error {
+	var problems []error
+	if !oneOf(l.Level, "debug", "info", "warn", "error") {
+		problems = append(problems, invalid("logging.level", "LOG_LEVEL", l.Level, "debug, info, warn or error"))
+	}
+	if !oneOf(l.Format, "json", "text") {
+		problems = append(problems, invalid("logging.format", "LOG_FORMAT", l.Format, "json or text"))
+	}
+	return errors.Join(problems...)
+}
+
+// Validate checks the exporter is known and the service is named
+func (t Tracing) Validate() error {
+	var problems []error
+	if !oneOf(t.Exporter, "otlp", "stdout", "none") {
+		problems = append(problems, invalid("tracing.exporter", "OTEL_TRACES_EXPORTER", t.Exporter, "otlp, stdout or none"))
+	}
+	if t.ServiceName == "" {
+		problems = append(problems, required("tracing.service_name", "OTEL_SERVICE_NAME"))
+	}
+	return errors.Join(problems...)
+}
+
+// -------------------- Messages --------------------
+
+func required(key, env string) error {
+	return fmt.Errorf("%s (%s) is required", key, env)
+}
+
+func invalid(key, env string, value interface{}, expected string) error {
+	return fmt.Errorf("%s (%s) is %q; expected %s", key, env, fmt.Sprint(value), expected)
+}
+
+func port(key, env string, value int) error {
+	if value < 1 || value > 65535 {
+		return invalid(key, env, value, "a port between 1 and 65535")
+	}
+	return nil
+}
+
+func positive(key, env string, value time.Duration) error {
+	if value <= 0 {
+		return invalid(key, env, value, "a positive duration such as 30s")
+	}
+	return nil
+}
+
+func proxies(key, env string, values []string) error {
+	for _, value := range values {
+		if net.ParseIP(value) == nil {
+			if _, _, err := net.ParseCIDR(value); err != nil {
+				return invalid(key, env, value, "IP addresses or CIDR ranges")
+			}
+		}
+	}
+	return nil
+}
+
+func absoluteURL(key, env, value string) error {
+	if parsed, err := url.Parse(value); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return invalid(key, env, value, "an absolute URL such as https://app.example.com/path")
+	}
+	return nil
+}
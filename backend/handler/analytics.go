@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/pkg"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// defaultAnalyticsPeriod is the period reported when from is omitted
+const defaultAnalyticsPeriod = 28 * 24 * time.Hour
+
+// Get Scheduling Analytics
+//
+// Query parameters: from and to (RFC 3339 or YYYY-MM-DD; to is exclusive and
+// defaults to now, from to four weeks before it), interviewer_id, group_by (a
+// comma-separated list of interviewer, event_type and week; default interviewer),
+// tz (IANA time zone for weeks and hours of day) and source (live or rollups).
+// Interviewers only see their own numbers.
+func GetSchedulingAnalytics(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	filter := pkg.AnalyticsFilter{Source: c.Query("source"), GroupBy: []string{pkg.GroupByInterviewer}}
+	var err error
+	if filter.From, err = parseTimeParam(c.Query("from")); err != nil {
+		c.Error(pkg.Validation("invalid_from", "Invalid from. Use RFC 3339 or YYYY-MM-DD."))
+		return
+	}
+	if filter.To, err = parseTimeParam(c.Query("to")); err != nil {
+		c.Error(pkg.Validation("invalid_to", "Invalid to. Use RFC 3339 or YYYY-MM-DD."))
+		return
+	}
+	if filter.To.IsZero() {
+		filter.To = time.Now().UTC()
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.Add(-defaultAnalyticsPeriod)
+	}
+
+	if param, ok := c.GetQuery("group_by"); ok {
+		filter.GroupBy = []string{}
+		for _, dimension := range strings.Split(param, ",") {
+			if dimension = strings.TrimSpace(dimension); dimension != "" {
+				filter.GroupBy = append(filter.GroupBy, dimension)
+			}
+		}
+	}
+	if param := c.Query("tz"); param != "" {
+		if filter.Location, err = time.LoadLocation(param); err != nil {
+			c.Error(pkg.Validation("invalid_time_zone", "Invalid tz. Use an IANA time zone such as Europe/Berlin."))
+			return
+		}
+	}
+	if param := c.Query("interviewer_id"); param != "" {
+		id, err := strconv.Atoi(param)
+		if err != nil || id <= 0 {
+			c.Error(pkg.Validation("invalid_interviewer_id", "Invalid interviewer ID"))
+			return
+		}
+		filter.InterviewerID = uint(id)
+	}
+	if claims := auth.ClaimsFrom(c); claims != nil && claims.Role == auth.RoleInterviewer {
+		filter.InterviewerID = claims.InterviewerID
+	}
+
+	report, err := pkg.SchedulingAnalytics(db, filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Refresh Analytics Rollups
+//
+// Recomputes the stored weekly rollups of the UTC weeks touching from and to
+// (default: the last four weeks), for reports with source=rollups.
+func RefreshAnalyticsRollups(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	from, err := parseTimeParam(c.Query("from"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_from", "Invalid from. Use RFC 3339 or YYYY-MM-DD."))
+		return
+	}
+	to, err := parseTimeParam(c.Query("to"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_to", "Invalid to. Use RFC 3339 or YYYY-MM-DD."))
+		return
+	}
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultAnalyticsPeriod)
+	}
+
+	count, err := pkg.RefreshAnalyticsRollups(db, from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Analytics rollups refreshed", "rollups": count})
+}
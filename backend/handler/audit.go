@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/logging"
+	"BookingTimeSlot/backend/pkg"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// RequestID tags each request with the caller's X-Request-ID, or a new one, and echoes it back.
+// The ID is attached to every log line written with the request's context.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader("X-Request-ID")
+		if requestID == "" || len(requestID) > 128 {
+			raw := make([]byte, 16)
+			rand.Read(raw)
+			requestID = hex.EncodeToString(raw)
+		}
+
+		c.Set("request_id", requestID)
+		c.Header("X-Request-ID", requestID)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), "request_id", requestID))
+		c.Next()
+	}
+}
+
+// LogFields attaches key-value pairs, e.g. "booking_id", to every later log line of
+// the request and returns db with them attached to the lines of its queries too.
+func LogFields(c *gin.Context, db *gorm.DB, args ...any) *gorm.DB {
+	c.Request = c.Request.WithContext(logging.With(c.Request.Context(), args...))
+	return db.WithContext(logging.With(db.Statement.Context, args...))
+}
+
+// AuditActor logs the request's database changes against the authenticated caller
+// and request ID. It must run after ResolveOrganization, which replaces "db".
+func AuditActor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := c.MustGet("db").(*gorm.DB)
+
+		actor := "anonymous"
+		if claims := auth.ClaimsFrom(c); claims != nil {
+			actor = claims.Role + ":" + claims.Subject
+		}
+
+		c.Set("db", db.WithContext(pkg.WithAuditContext(db.Statement.Context, actor, c.GetString("request_id"))))
+		c.Next()
+	}
+}
+
+// Get Audit Logs
+//
+// Query parameters: actor, action, entity_type, entity_id, request_id, from and
+// to (RFC 3339 or YYYY-MM-DD; to is exclusive), limit and offset.
+func GetAuditLogs(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	filter := pkg.AuditLogFilter{
+		Actor:      c.Query("actor"),
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		RequestID:  c.Query("request_id"),
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(c.Query("from")); err != nil {
+		c.Error(pkg.Validation("invalid_from", "Invalid from. Use RFC 3339 or YYYY-MM-DD."))
+		return
+	}
+	if filter.To, err = parseTimeParam(c.Query("to")); err != nil {
+		c.Error(pkg.Validation("invalid_to", "Invalid to. Use RFC 3339 or YYYY-MM-DD."))
+		return
+	}
+	if filter.Limit, filter.Offset, err = parsePage(c, defaultAuditLimit, maxAuditLimit); err != nil {
+		c.Error(err)
+		return
+	}
+
+	logs, total, err := pkg.ListAuditLogs(db, filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"audit_logs": logs, "total": total, "limit": filter.Limit, "offset": filter.Offset})
+}
+
+// parseTimeParam accepts an RFC 3339 timestamp or a UTC date; empty means unbounded
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// parsePage reads the limit and offset query parameters of a paginated list
+func parsePage(c *gin.Context, defaultLimit, maxLimit int) (int, int, error) {
+	limit, offset := defaultLimit, 0
+	var err error
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit <= 0 || limit > maxLimit {
+			return 0, 0, pkg.Validation("invalid_limit", "Invalid limit")
+		}
+	}
+	if offsetParam := c.Query("offset"); offsetParam != "" {
+		if offset, err = strconv.Atoi(offsetParam); err != nil || offset < 0 {
+			return 0, 0, pkg.Validation("invalid_offset", "Invalid offset")
+		}
+	}
+	return limit, offset, nil
+}
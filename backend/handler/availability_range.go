@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/pkg"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	maxRangeDays       = 92 // longest from/to span accepted in one request
+	defaultPageDays    = 7  // days per page when listing slots
+	defaultSummaryDays = 62 // days per page in summary mode
+)
+
+// Get Availability Range
+//
+// Query parameters: from, to (YYYY-MM-DD, inclusive), interviewer_id (repeatable
+// or comma separated), event_type (ID or slug), tz (IANA name), summary=true for
+// day counts only, limit (days per page) and cursor from a previous response.
+func GetAvailabilityRange(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_date", "Invalid from date. Use YYYY-MM-DD."))
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_date", "Invalid to date. Use YYYY-MM-DD."))
+		return
+	}
+	if to.Before(from) {
+		c.Error(pkg.Validation("invalid_date_range", "to must not be before from"))
+		return
+	}
+	if to.Sub(from) >= maxRangeDays*24*time.Hour {
+		c.Error(pkg.Validation("invalid_date_range", "Date range is limited to "+strconv.Itoa(maxRangeDays)+" days"))
+		return
+	}
+
+	location := time.UTC
+	if tz := c.Query("tz"); tz != "" {
+		if location, err = time.LoadLocation(tz); err != nil {
+			c.Error(pkg.Validation("invalid_time_zone", "Invalid time zone"))
+			return
+		}
+	}
+
+	interviewerIDs, err := parseIDList(c.QueryArray("interviewer_id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_interviewer_id", "Invalid interviewer ID"))
+		return
+	}
+
+	durationMinutes := 0
+	if eventTypeParam := c.Query("event_type"); eventTypeParam != "" {
+		eventType, err := pkg.FindEventType(db, eventTypeParam)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		durationMinutes = eventType.DurationMinutes
+	}
+
+	summary := c.Query("summary") == "true"
+	limit := defaultPageDays
+	if summary {
+		limit = defaultSummaryDays
+	}
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit <= 0 || limit > maxRangeDays {
+			c.Error(pkg.Validation("invalid_limit", "Invalid limit"))
+			return
+		}
+	}
+
+	// The cursor is the first day of the next page
+	pageStart := from
+	if cursor := c.Query("cursor"); cursor != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			c.Error(pkg.Validation("invalid_cursor", "Invalid cursor"))
+			return
+		}
+		if pageStart, err = time.Parse("2006-01-02", string(decoded)); err != nil || pageStart.Before(from) || pageStart.After(to) {
+			c.Error(pkg.Validation("invalid_cursor", "Invalid cursor"))
+			return
+		}
+	}
+	pageEnd := pageStart.AddDate(0, 0, limit-1)
+	nextCursor := ""
+	if pageEnd.Before(to) {
+		nextCursor = base64.RawURLEncoding.EncodeToString([]byte(pageEnd.AddDate(0, 0, 1).Format("2006-01-02")))
+	} else {
+		pageEnd = to
+	}
+
+	days, err := pkg.GenerateSlotRange(db, pkg.SlotRangeQuery{
+		From:            pageStart.Format("2006-01-02"),
+		To:              pageEnd.Format("2006-01-02"),
+		InterviewerIDs:  interviewerIDs,
+		DurationMinutes: durationMinutes,
+		Location:        location,
+		CountOnly:       summary,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Summary mode lists only the days that have openings, without the slots
+	if summary {
+		available := []pkg.DaySlots{}
+		for _, day := range days {
+			if day.Count > 0 {
+				available = append(available, day)
+			}
+		}
+		days = available
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":        pageStart.Format("2006-01-02"),
+		"to":          pageEnd.Format("2006-01-02"),
+		"time_zone":   location.String(),
+		"days":        days,
+		"next_cursor": nextCursor,
+	})
+}
+
+// parseIDList accepts repeated and comma-separated numeric IDs.
+func parseIDList(values []string) ([]uint, error) {
+	var ids []uint
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			id, err := strconv.Atoi(part)
+			if err != nil || id <= 0 {
+				return nil, fmt.Errorf("invalid ID %q", part)
+			}
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids, nil
+}
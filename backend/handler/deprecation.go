@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LegacyDeprecatedAt is when the unversioned routes were deprecated in favour of /api/v1
+var LegacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// LegacySunset is when the unversioned routes will be removed; zero omits the Sunset header
+var LegacySunset = LegacyDeprecatedAt.AddDate(0, 6, 0)
+
+// legacyUsage counts calls per deprecated route, e.g. "GET /api/holidays"
+var legacyUsage = struct {
+	sync.Mutex
+	routes map[string]*LegacyRouteUsage
+}{routes: map[string]*LegacyRouteUsage{}}
+
+// LegacyRouteUsage is how often a deprecated route was called since startup
+type LegacyRouteUsage struct {
+	Route     string `json:"route"`
+	Successor string `json:"successor"`
+	Calls     int64  `json:"calls"`
+}
+
+// Deprecated marks a legacy route as an adapter of successor, a /api/v1 path in
+// gin syntax. Responses carry Deprecation, Sunset and successor Link headers, and
+// every call is counted.
+func Deprecated(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		legacyUsage.Lock()
+		if legacyUsage.routes[route] == nil {
+			legacyUsage.routes[route] = &LegacyRouteUsage{Route: route, Successor: successor}
+		}
+		legacyUsage.routes[route].Calls++
+		legacyUsage.Unlock()
+
+		c.Header("Deprecation", "@"+strconv.FormatInt(LegacyDeprecatedAt.Unix(), 10))
+		if !LegacySunset.IsZero() {
+			c.Header("Sunset", LegacySunset.UTC().Format(http.TimeFormat))
+		}
+		c.Header("Link", "<"+successorPath(successor, c.Params)+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
+
+// successorPath fills the successor's path parameters from the legacy request
+func successorPath(successor string, params gin.Params) string {
+	segments := strings.Split(successor, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			if value, found := params.Get(name); found {
+				segments[i] = value
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Get Legacy Usage
+func GetLegacyUsage(c *gin.Context) {
+	legacyUsage.Lock()
+	usage := make([]LegacyRouteUsage, 0, len(legacyUsage.routes))
+	for _, route := range legacyUsage.routes {
+		usage = append(usage, *route)
+	}
+	legacyUsage.Unlock()
+
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Calls != usage[j].Calls {
+			return usage[i].Calls > usage[j].Calls
+		}
+		return usage[i].Route < usage[j].Route
+	})
+
+	response := gin.H{"routes": usage, "deprecated_at": LegacyDeprecatedAt}
+	if !LegacySunset.IsZero() {
+		response["sunset"] = LegacySunset
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/pkg"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Problem is an RFC 9457 problem details body, returned by every failed API request.
+// Clients branch on Code, e.g. "slot_already_booked"; Detail is for people.
+type Problem struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Code      string            `json:"code"`
+	Instance  string            `json:"instance,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"` // invalid fields, e.g. intake answers
+}
+
+// problemStatuses maps each kind of domain error to its HTTP status.
+var problemStatuses = []struct {
+	kind   error
+	status int
+}{
+	{pkg.ErrNotFound, http.StatusNotFound},
+	{pkg.ErrConflict, http.StatusConflict},
+	{pkg.ErrValidation, http.StatusBadRequest},
+	{pkg.ErrPastSlot, http.StatusBadRequest},
+	{pkg.ErrGone, http.StatusGone},
+	{pkg.ErrUnauthorized, http.StatusUnauthorized},
+	{pkg.ErrForbidden, http.StatusForbidden},
+	{pkg.ErrRateLimited, http.StatusTooManyRequests},
+	{pkg.ErrUnprocessable, http.StatusUnprocessableEntity},
+	{pkg.ErrPreconditionFailed, http.StatusPreconditionFailed},
+	{pkg.ErrPreconditionRequired, http.StatusPreconditionRequired},
+}
+
+// ProblemDetails turns the last error a handler or middleware recorded with c.Error
+// into a problem details response. Register it first so it sees every error.
+// Errors tagged gin.ErrorTypeBind are invalid request bodies; errors that are not
+// domain errors become a 500 whose cause is logged, not shown.
+func ProblemDetails() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		writeProblem(c)
+	}
+}
+
+// writeProblem responds with the last recorded error, unless there is none or a
+// response was already written
+func writeProblem(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+	last := c.Errors.Last()
+
+	problem := newProblem(last.Err)
+	if last.IsType(gin.ErrorTypeBind) {
+		problem = Problem{Status: http.StatusBadRequest, Code: "invalid_request", Detail: last.Err.Error()}
+	}
+	if problem.Status == http.StatusInternalServerError {
+		slog.ErrorContext(c.Request.Context(), "Request failed", "method", c.Request.Method, "path", c.Request.URL.Path, "error", last.Err)
+	}
+
+	problem.Type = "about:blank"
+	problem.Title = http.StatusText(problem.Status)
+	problem.Instance = c.Request.URL.Path
+	problem.RequestID = c.GetString("request_id")
+
+	c.Header("Content-Type", "application/problem+json")
+	c.JSON(problem.Status, problem)
+}
+
+// newProblem describes err without the request details
+func newProblem(err error) Problem {
+	var answerErrors pkg.AnswerErrors
+	if errors.As(err, &answerErrors) {
+		return Problem{Status: http.StatusBadRequest, Code: "invalid_answers", Detail: err.Error(), Errors: answerErrors}
+	}
+
+	var domainErr *pkg.Error
+	if !errors.As(err, &domainErr) {
+		return Problem{Status: http.StatusInternalServerError, Code: "internal_error", Detail: "An unexpected error occurred"}
+	}
+	for _, mapping := range problemStatuses {
+		if errors.Is(domainErr, mapping.kind) {
+			return Problem{Status: mapping.status, Code: domainErr.Code, Detail: domainErr.Message}
+		}
+	}
+	return Problem{Status: http.StatusInternalServerError, Code: domainErr.Code, Detail: domainErr.Message}
+}
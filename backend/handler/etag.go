@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/pkg"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireIfMatch rejects changes to versioned resources sent without If-Match
+// with 428; turned off, such changes apply to whatever version is current
+var RequireIfMatch = true
+
+// etag is the entity tag of a resource at version
+func etag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// setETag tags the response with the version of the resource it returns
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", etag(version))
+}
+
+// ifMatch checks the If-Match header against the current version of the
+// resource a request changes. It returns the version the write must still
+// find, or 0 when the header is absent or "*", which any version matches.
+func ifMatch(c *gin.Context, version uint) (uint, error) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		if RequireIfMatch {
+			return 0, pkg.PreconditionRequired("if_match_required", "Send the ETag of the version you are changing in If-Match")
+		}
+		return 0, nil
+	}
+
+	current := etag(version)
+	for _, tag := range strings.Split(header, ",") {
+		switch strings.TrimSpace(tag) {
+		case "*":
+			return 0, nil
+		case current:
+			return version, nil
+		}
+	}
+	return 0, pkg.PreconditionFailed("version_mismatch", "The resource was changed by someone else; reload it and try again")
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/pkg"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultEventLimit = 50
+	maxEventLimit     = 500
+)
+
+// EventRequest creates a calendar event
+type EventRequest struct {
+	BookingID     uint      `json:"booking_id"`
+	TimeSlotID    uint      `json:"time_slot_id"`
+	InterviewerID uint      `json:"interviewer_id" binding:"required"`
+	BookedBy      string    `json:"booked_by" binding:"required"`
+	Title         string    `json:"title" binding:"required"`
+	Description   string    `json:"description"`
+	StartTime     time.Time `json:"start_time" binding:"required"`
+	EndTime       time.Time `json:"end_time" binding:"required"`
+	Status        string    `json:"status"` // defaults to "scheduled"
+}
+
+// EventUpdateRequest changes only the fields it sets
+type EventUpdateRequest struct {
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	Status      *string    `json:"status"`
+	StartTime   *time.Time `json:"start_time"`
+	EndTime     *time.Time `json:"end_time"`
+}
+
+// Get Events
+//
+// Query parameters: booking_id, interviewer_id, candidate (part of booked_by),
+// status, from and to (RFC 3339 or YYYY-MM-DD, on start_time; to is exclusive),
+// limit and offset. Interviewers only see their own events.
+func GetEvents(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	filter := pkg.EventFilter{
+		Candidate: c.Query("candidate"),
+		Status:    c.Query("status"),
+	}
+
+	var err error
+	if param := c.Query("booking_id"); param != "" {
+		id, err := strconv.Atoi(param)
+		if err != nil || id <= 0 {
+			c.Error(pkg.Validation("invalid_booking_id", "Invalid booking ID"))
+			return
+		}
+		filter.BookingID = uint(id)
+	}
+	if param := c.Query("interviewer_id"); param != "" {
+		id, err := strconv.Atoi(param)
+		if err != nil || id <= 0 {
+			c.Error(pkg.Validation("invalid_interviewer_id", "Invalid interviewer ID"))
+			return
+		}
+		filter.InterviewerID = uint(id)
+	}
+	if claims := auth.ClaimsFrom(c); claims != nil && claims.Role == auth.RoleInterviewer {
+		filter.InterviewerID = claims.InterviewerID
+	}
+	if filter.From, err = parseTimeParam(c.Query("from")); err != nil {
+		c.Error(pkg.Validation("invalid_from", "Invalid from. Use RFC 3339 or YYYY-MM-DD."))
+		return
+	}
+	if filter.To, err = parseTimeParam(c.Query("to")); err != nil {
+		c.Error(pkg.Validation("invalid_to", "Invalid to. Use RFC 3339 or YYYY-MM-DD."))
+		return
+	}
+	if filter.Limit, filter.Offset, err = parsePage(c, defaultEventLimit, maxEventLimit); err != nil {
+		c.Error(err)
+		return
+	}
+
+	events, total, err := pkg.ListEvents(db, filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events, "total": total, "limit": filter.Limit, "offset": filter.Offset})
+}
+
+// Get Event
+func GetEvent(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	event, ok := findOwnEvent(c, db)
+	if !ok {
+		return
+	}
+
+	setETag(c, event.Version)
+	c.JSON(http.StatusOK, gin.H{"event": event})
+}
+
+// Create Event
+func CreateEvent(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	var req EventRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if claims := auth.ClaimsFrom(c); claims != nil && claims.Role == auth.RoleInterviewer && req.InterviewerID != claims.InterviewerID {
+		c.Error(pkg.Forbidden("not_event_owner", "You can only create your own events"))
+		return
+	}
+
+	event, err := pkg.CreateEvent(db, pkg.Event{
+		BookingID:     req.BookingID,
+		TimeSlotID:    req.TimeSlotID,
+		InterviewerID: req.InterviewerID,
+		BookedBy:      req.BookedBy,
+		Title:         req.Title,
+		Description:   req.Description,
+		StartTime:     req.StartTime.UTC(),
+		EndTime:       req.EndTime.UTC(),
+		Status:        req.Status,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Event created successfully", "data": event})
+}
+
+// Update Event
+//
+// With If-Match, the event must still have that ETag.
+func UpdateEvent(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	var req EventUpdateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	existing, ok := findOwnEvent(c, db)
+	if !ok {
+		return
+	}
+	ifVersion, err := ifMatch(c, existing.Version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	event, err := pkg.UpdateEvent(db, existing.ID, pkg.EventUpdate{
+		Title:       req.Title,
+		Description: req.Description,
+		Status:      req.Status,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		IfVersion:   ifVersion,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, event.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Event updated successfully", "data": event})
+}
+
+// Delete Event
+//
+// With If-Match, the event must still have that ETag.
+func DeleteEvent(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	event, ok := findOwnEvent(c, db)
+	if !ok {
+		return
+	}
+	ifVersion, err := ifMatch(c, event.Version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := pkg.DeleteEvent(db, event.ID, ifVersion); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully"})
+}
+
+// findOwnEvent loads the event of the :id parameter; interviewers may only reach their own
+func findOwnEvent(c *gin.Context, db *gorm.DB) (*pkg.Event, bool) {
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_event_id", "Invalid event ID"))
+		return nil, false
+	}
+
+	event, err := pkg.GetEventByID(db, uint(eventID))
+	if err != nil {
+		c.Error(err)
+		return nil, false
+	}
+
+	if claims := auth.ClaimsFrom(c); claims != nil && claims.Role == auth.RoleInterviewer && event.InterviewerID != claims.InterviewerID {
+		c.Error(pkg.Forbidden("not_event_owner", "You can only manage your own events"))
+		return nil, false
+	}
+	return event, true
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/pkg"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// EventTypeRequest creates a bookable kind of interview
+type EventTypeRequest struct {
+	Name            string `json:"name" binding:"required"`
+	Slug            string `json:"slug" binding:"required"`
+	Description     string `json:"description,omitempty"`
+	DurationMinutes int    `json:"duration_minutes" binding:"required"`
+}
+
+// Create Event Type
+func CreateEventType(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	var req EventTypeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	eventType, err := pkg.CreateEventType(db, req.Name, req.Slug, req.Description, req.DurationMinutes)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Event type created successfully", "data": eventType})
+}
+
+// Get Event Types
+func GetEventTypes(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	eventTypes, err := pkg.ListEventTypes(db)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"event_types": eventTypes})
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/pkg"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Export Bookings
+//
+// Streams the organization's bookings as CSV, NDJSON or ICS (format, default csv).
+// Query parameters: from and to (RFC 3339 or YYYY-MM-DD, on start_time; to is
+// exclusive), interviewer_id and status. Interviewers only export their own bookings.
+func ExportBookings(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	format := c.DefaultQuery("format", pkg.ExportCSV)
+	contentType, ok := pkg.ExportContentTypes[format]
+	if !ok {
+		c.Error(pkg.Validation("invalid_format", "Invalid format. Use csv, ndjson or ics."))
+		return
+	}
+
+	filter := pkg.BookingExportFilter{Status: c.Query("status")}
+	var err error
+	if filter.From, err = parseTimeParam(c.Query("from")); err != nil {
+		c.Error(pkg.Validation("invalid_from", "Invalid from. Use RFC 3339 or YYYY-MM-DD."))
+		return
+	}
+	if filter.To, err = parseTimeParam(c.Query("to")); err != nil {
+		c.Error(pkg.Validation("invalid_to", "Invalid to. Use RFC 3339 or YYYY-MM-DD."))
+		return
+	}
+	if param := c.Query("interviewer_id"); param != "" {
+		id, err := strconv.Atoi(param)
+		if err != nil || id <= 0 {
+			c.Error(pkg.Validation("invalid_interviewer_id", "Invalid interviewer ID"))
+			return
+		}
+		filter.InterviewerID = uint(id)
+	}
+	if claims := auth.ClaimsFrom(c); claims != nil && claims.Role == auth.RoleInterviewer {
+		filter.InterviewerID = claims.InterviewerID
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="bookings.%s"`, format))
+	c.Status(http.StatusOK)
+
+	// Large exports may outlast the server's write timeout
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	// The status is already sent, so a failure can only cut the stream short
+	if count, err := pkg.ExportBookings(db, c.Writer, format, filter); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Booking export failed", "exported", count, "error", err)
+	}
+}
@@ -1,7 +1,12 @@
 package handler
 
 import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/config"
+	"BookingTimeSlot/backend/pkg"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math/rand"
 	"net/http"
 	"strconv"
@@ -15,33 +20,28 @@ import (
 // Availability model
 type Availability struct {
 	gorm.Model
-	InterviewerID uint      `json:"interviewer_id" binding:"required" gorm:"index"`
-	StartTime     time.Time `json:"start_time" binding:"required" gorm:"type:timestamp"`
-	EndTime       time.Time `json:"end_time" binding:"required" gorm:"type:timestamp"`
-	TimeZone      string    `json:"time_zone,omitempty"`
-	WorkingDays   []int     `json:"working_days" gorm:"type:integer[]" binding:"required"`
-	BufferMinutes int       `json:"buffer_minutes,omitempty"`
-	Booked        bool      `json:"booked" gorm:"default:false"`
-	BookedBy      string    `json:"booked_by,omitempty"`
-}
-
-// Updated Booking model with only required fields
-type Booking struct {
-	gorm.Model
-	SlotID uint   `json:"slot_id" binding:"required"`
-	Name   string `json:"name" binding:"required"`
-	Email  string `json:"email" binding:"required"`
-	Date   string `json:"date" binding:"required"`
+	OrganizationID uint      `json:"organization_id" gorm:"index"`
+	InterviewerID  uint      `json:"interviewer_id" binding:"required" gorm:"index"`
+	StartTime      time.Time `json:"start_time" binding:"required" gorm:"type:timestamp"`
+	EndTime        time.Time `json:"end_time" binding:"required" gorm:"type:timestamp"`
+	TimeZone       string    `json:"time_zone,omitempty"`
+	WorkingDays    []int     `json:"working_days" gorm:"type:integer[]" binding:"required"`
+	BufferMinutes  int       `json:"buffer_minutes,omitempty"`
+	Booked         bool      `json:"booked" gorm:"default:false"`
+	BookedBy       string    `json:"booked_by,omitempty"`
+	Version        uint      `json:"version" gorm:"not null;default:1"` // counts changes; see pkg.RegisterVersioning
 }
 
 // Booking request struct
 type BookingRequest struct {
-	SlotID     uint   `json:"slot_id" binding:"required"`
-	BookerName string `json:"booker_name" binding:"required"`
-	UserID     uint   `json:"user_id" binding:"required"`
-	Name       string `json:"name" binding:"required"`
-	Email      string `json:"email" binding:"required"`
-	Date       string `json:"date" binding:"required"`
+	SlotID      uint              `json:"slot_id" binding:"required"`
+	BookerName  string            `json:"booker_name" binding:"required"`
+	UserID      uint              `json:"user_id" binding:"required"`
+	Name        string            `json:"name" binding:"required"`
+	Email       string            `json:"email" binding:"required"`
+	Date        string            `json:"date" binding:"required"`
+	EventTypeID uint              `json:"event_type_id,omitempty"`
+	Answers     map[string]string `json:"answers,omitempty"` // intake answers keyed by question key
 }
 
 // Middleware to attach DB instance
@@ -53,9 +53,8 @@ func AttachDB(db *gorm.DB) gin.HandlerFunc {
 }
 
 // Initialize database connection
-func InitDB() (*gorm.DB, error) {
-	dsn := "user=youruser password=yourpassword dbname=yourdbname port=5432 sslmode=disable"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+func InitDB(cfg config.Database) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -80,29 +79,41 @@ func SetInterviewerAvailability(c *gin.Context) {
 	var availability Availability
 
 	if err := c.ShouldBindJSON(&availability); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
 	if len(availability.WorkingDays) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Working days must be specified"})
+		c.Error(pkg.Validation("working_days_required", "Working days must be specified"))
 		return
 	}
 
 	for _, day := range availability.WorkingDays {
 		if day < 0 || day > 6 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid working day: %d", day)})
+			c.Error(pkg.Validation("invalid_working_day", fmt.Sprintf("Invalid working day: %d", day)))
 			return
 		}
 	}
 
 	if availability.StartTime.After(availability.EndTime) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Start time must be before end time"})
+		c.Error(pkg.Validation("invalid_time_range", "Start time must be before end time"))
+		return
+	}
+	if err := checkInterviewerWrite(c, db, availability.InterviewerID, "not_own_availability", "You can only set your own availability"); err != nil {
+		c.Error(err)
 		return
 	}
 
-	if err := db.Create(&availability).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save availability"})
+	// Windows of one interviewer may not overlap unless merge=true folds them together
+	availability.OrganizationID = pkg.CurrentOrganization(db)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := fitAvailability(tx, &availability, c.Query("merge") == "true"); err != nil {
+			return err
+		}
+		return pkg.OverlapConstraintError(tx.Create(&availability).Error)
+	})
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -115,13 +126,13 @@ func GetAvailableTimeSlots(c *gin.Context) {
 	date := c.Query("date")
 
 	if date == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Date is required"})
+		c.Error(pkg.Validation("date_required", "Date is required"))
 		return
 	}
 
 	startDate, err := time.Parse("2006-01-02", date)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		c.Error(pkg.Validation("invalid_date", "Invalid date format"))
 		return
 	}
 
@@ -130,7 +141,13 @@ func GetAvailableTimeSlots(c *gin.Context) {
 
 	var slots []Availability
 	if err := db.Where("start_time >= ? AND end_time <= ? AND booked = ?", start, end, false).Find(&slots).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching slots"})
+		c.Error(err)
+		return
+	}
+
+	slots, err = withoutHeldSlots(db, slots)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -142,19 +159,19 @@ func GetInterviewerAvailability(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	interviewerID, err := strconv.Atoi(c.Param("interviewer_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interviewer ID"})
+		c.Error(pkg.Validation("invalid_interviewer_id", "Invalid interviewer ID"))
 		return
 	}
 
 	date := c.Query("date")
 	if date == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Date is required"})
+		c.Error(pkg.Validation("date_required", "Date is required"))
 		return
 	}
 
 	startDate, err := time.Parse("2006-01-02", date)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		c.Error(pkg.Validation("invalid_date", "Invalid date format"))
 		return
 	}
 
@@ -162,12 +179,44 @@ func GetInterviewerAvailability(c *gin.Context) {
 	end := start.Add(24 * time.Hour)
 	var slots []Availability
 
+	// Holidays and date overrides take precedence over the normal working days
+	rule, err := pkg.ResolveDateRule(db, uint(interviewerID), date)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
 	if err := db.Where("interviewer_id = ? AND start_time >= ? AND end_time <= ?", interviewerID, start, end).Find(&slots).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching interviewer availability"})
+		c.Error(err)
+		return
+	}
+
+	slots, err = withoutHeldSlots(db, slots)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"availability": applyDateRule(rule, uint(interviewerID), slots), "blocked": rule.Blocked, "reason": rule.Reason})
+}
+
+// Get Availability Window
+func GetAvailabilityWindow(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	windowID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_availability_id", "Invalid availability ID"))
+		return
+	}
+
+	var availability Availability
+	if err := db.First(&availability, windowID).Error; err != nil {
+		c.Error(pkg.NotFound("availability_not_found", "Availability not found"))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"availability": slots})
+	setETag(c, availability.Version)
+	c.JSON(http.StatusOK, gin.H{"availability": availability})
 }
 
 // Book a Time Slot
@@ -176,51 +225,65 @@ func BookTimeSlot(c *gin.Context) {
 	var req BookingRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	answers, err := pkg.ValidateAnswers(db, req.EventTypeID, req.Answers)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	var slot Availability
 	if err := db.First(&slot, req.SlotID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Slot not found"})
+		c.Error(pkg.NotFound("slot_not_found", "Slot not found"))
+		return
+	}
+	if err := LimitBooking(c, req.Email, slot.InterviewerID); err != nil {
+		c.Error(err)
 		return
 	}
 
 	if slot.Booked {
-		c.JSON(http.StatusConflict, gin.H{"error": "Slot already booked"})
+		c.Error(pkg.Conflict("slot_already_booked", "Slot already booked"))
+		return
+	}
+	if !slot.StartTime.After(time.Now()) {
+		c.Error(pkg.PastSlot("slot_in_past", "Cannot book a past slot"))
 		return
 	}
 
-	tx := db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unexpected error during booking"})
-		}
-	}()
-
-	slot.Booked = true
-	slot.BookedBy = req.BookerName
-	if err := tx.Save(&slot).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Booking failed"})
+	held, err := pkg.HeldSlotIDs(db, pkg.SlotKindAvailability)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if held[slot.ID] {
+		c.Error(pkg.Conflict("slot_held", "Slot is held for a waitlisted candidate"))
 		return
 	}
 
-	booking := Booking{
-		SlotID: slot.ID,
-		Name:   req.Name,
-		Email:  req.Email,
-		Date:   req.Date,
+	booking := pkg.Booking{
+		Name:          req.Name,
+		Email:         req.Email,
+		SlotID:        slot.ID,
+		SlotKind:      pkg.SlotKindAvailability,
+		InterviewerID: slot.InterviewerID,
+		EventTypeID:   req.EventTypeID,
+		StartTime:     slot.StartTime,
+		EndTime:       slot.EndTime,
+		Answers:       answers,
 	}
 
-	if err := tx.Create(&booking).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save booking"})
+	err = pkg.PlaceBooking(db, &booking, func(tx *gorm.DB) error {
+		return bookAvailability(tx, slot.ID, req.BookerName)
+	})
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	tx.Commit()
 	c.JSON(http.StatusOK, gin.H{"message": "Booking successful", "booking": booking})
 }
 
@@ -229,37 +292,112 @@ func CancelBooking(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	bookingID := c.Param("id")
 
-	var booking Booking
+	var booking pkg.Booking
 	if err := db.First(&booking, bookingID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		c.Error(pkg.NotFound("booking_not_found", "Booking not found"))
 		return
 	}
+	interviewerID := bookingInterviewerID(db, booking)
+	db = LogFields(c, db, "booking_id", booking.ID, "interviewer_id", interviewerID)
 
-	tx := db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unexpected error during cancellation"})
-		}
-	}()
-
-	var slot Availability
-	if err := db.First(&slot, booking.SlotID).Error; err == nil {
-		slot.Booked = false
-		slot.BookedBy = ""
-		if err := tx.Save(&slot).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update slot"})
+	// Interviewers may only cancel bookings of their own slots
+	if claims := auth.ClaimsFrom(c); claims != nil && claims.Role == auth.RoleInterviewer {
+		if interviewerID != claims.InterviewerID {
+			c.Error(pkg.Forbidden("not_booking_owner", "You can only cancel your own bookings"))
 			return
 		}
 	}
 
-	if err := tx.Delete(&booking).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking"})
+	if err := cancelBooking(db, booking); err != nil {
+		c.Error(err)
 		return
 	}
+	slog.InfoContext(c.Request.Context(), "Booking cancelled")
 
-	tx.Commit()
 	c.JSON(http.StatusOK, gin.H{"message": "Booking cancelled successfully"})
 }
+
+// cancelBooking marks a booking of an availability window cancelled and
+// releases the window, or cancels bookings of the pkg slot kinds through
+// pkg.UpdateBookingStatus
+func cancelBooking(db *gorm.DB, booking pkg.Booking) error {
+	if !isAvailabilityBooking(booking) {
+		return pkg.UpdateBookingStatus(db, booking.ID, "cancelled")
+	}
+
+	var slot Availability
+	slotFound := db.First(&slot, booking.SlotID).Error == nil
+
+	cancelled := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&pkg.Booking{}).Where("id = ? AND status <> ?", booking.ID, "cancelled").
+			Updates(map[string]interface{}{"status": "cancelled", "updated_at": time.Now()})
+		if result.Error != nil {
+			return errors.New("failed to cancel booking")
+		}
+		if result.RowsAffected == 0 {
+			return nil // cancelled before
+		}
+		cancelled = true
+
+		if slotFound {
+			if err := tx.Model(&slot).Updates(map[string]interface{}{"booked": false, "booked_by": ""}).Error; err != nil {
+				return errors.New("failed to update slot")
+			}
+		}
+		if err := pkg.RevokeManageToken(tx, booking.ID); err != nil {
+			return err
+		}
+		return pkg.CancelBookingEvents(tx, booking.ID)
+	})
+	if err != nil || !cancelled {
+		return err
+	}
+
+	// Offer the released slot to the waitlist before it becomes public again
+	if slotFound {
+		_, err := pkg.OfferFreedSlot(db, pkg.FreedSlot{
+			Kind:          pkg.SlotKindAvailability,
+			SlotID:        slot.ID,
+			InterviewerID: slot.InterviewerID,
+			EventTypeID:   booking.EventTypeID,
+			StartTime:     slot.StartTime,
+			EndTime:       slot.EndTime,
+		})
+		if err != nil {
+			slog.ErrorContext(db.Statement.Context, "Failed to offer cancelled booking to the waitlist", "booking_id", booking.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// bookAvailability marks an open availability window booked by name, failing
+// when a concurrent booking took it first
+func bookAvailability(tx *gorm.DB, slotID uint, name string) error {
+	result := tx.Model(&Availability{}).Where("id = ? AND booked = ?", slotID, false).
+		Updates(map[string]interface{}{"booked": true, "booked_by": name})
+	if result.Error != nil {
+		return errors.New("failed to update slot")
+	}
+	if result.RowsAffected == 0 {
+		return pkg.Conflict("slot_already_booked", "slot already booked")
+	}
+	return nil
+}
+
+// isAvailabilityBooking reports whether SlotID refers to an Availability window.
+func isAvailabilityBooking(booking pkg.Booking) bool {
+	return booking.SlotKind == pkg.SlotKindAvailability
+}
+
+// bookingInterviewerID returns the interviewer a booking is with
+func bookingInterviewerID(db *gorm.DB, booking pkg.Booking) uint {
+	if booking.InterviewerID != 0 || !isAvailabilityBooking(booking) {
+		return booking.InterviewerID
+	}
+	var slot Availability
+	if err := db.Unscoped().First(&slot, booking.SlotID).Error; err != nil {
+		return 0
+	}
+	return slot.InterviewerID
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/pkg"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// IdempotencyKeyHeader carries a client-chosen key, e.g. a UUID, that makes
+// retries of a POST, PUT, PATCH or DELETE safe
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength is the longest key stored
+const maxIdempotencyKeyLength = 255
+
+// maxIdempotentBodyBytes bounds the body read to fingerprint a request; no
+// route accepts more than a slot import
+const maxIdempotentBodyBytes = maxSlotImportBytes
+
+// Idempotency replays the first response to a mutating request when it is
+// retried with the same Idempotency-Key, so a booking form retrying after a
+// timeout gets its booking back rather than a conflict. Reusing a key for a
+// different request is rejected. Keys are kept per organization and caller,
+// so it must run after AuditActor. Requests without the header are untouched.
+func Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !mutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.Error(pkg.Validation("invalid_idempotency_key", "Idempotency-Key must be at most "+strconv.Itoa(maxIdempotencyKeyLength)+" characters"))
+			c.Abort()
+			return
+		}
+		db := c.MustGet("db").(*gorm.DB)
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes))
+		if err != nil {
+			c.Error(pkg.Validation("invalid_request", "Failed to read request body"))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, err := pkg.ClaimIdempotencyKey(db, idempotencyScope(c, db), key, requestHash(c.Request, body))
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if record.Finished() {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.StatusCode, record.ContentType, record.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		writeProblem(c) // now rather than in ProblemDetails, so errors are stored too
+		c.Writer = recorder.ResponseWriter
+
+		// Server errors and rate limits are not final: a retry runs the request again
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			err = pkg.ReleaseIdempotencyKey(db, record)
+		} else {
+			err = pkg.SaveIdempotentResponse(db, record, status, c.Writer.Header().Get("Content-Type"), recorder.body.Bytes())
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// mutating reports whether requests with method change state
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// idempotencyScope is the organization and caller a key belongs to. Anonymous
+// callers, e.g. candidates, are told apart by their address, so one cannot
+// replay another's response by guessing their key.
+func idempotencyScope(c *gin.Context, db *gorm.DB) string {
+	caller := "anonymous:" + c.ClientIP()
+	if claims := auth.ClaimsFrom(c); claims != nil {
+		caller = claims.Role + ":" + claims.Subject
+	}
+	return strconv.FormatUint(uint64(pkg.CurrentOrganization(db)), 10) + ":" + caller
+}
+
+// requestHash fingerprints a request's method, path, query and body
+func requestHash(request *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, request.Method+" "+request.URL.RequestURI()+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder copies the response body as it is written
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
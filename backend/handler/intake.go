@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// IntakeQuestionRequest adds a custom booking form field to an event type
+type IntakeQuestionRequest struct {
+	Key       string   `json:"key" binding:"required"`
+	Label     string   `json:"label" binding:"required"`
+	Type      string   `json:"type" binding:"required"`
+	Required  bool     `json:"required"`
+	Options   []string `json:"options,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	MaxLength int      `json:"max_length,omitempty"`
+	Position  int      `json:"position"`
+}
+
+// Create Intake Question
+func CreateIntakeQuestion(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	eventTypeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_event_type_id", "Invalid event type ID"))
+		return
+	}
+
+	var req IntakeQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	question, err := pkg.CreateIntakeQuestion(db, pkg.IntakeQuestion{
+		EventTypeID: uint(eventTypeID),
+		Key:         req.Key,
+		Label:       req.Label,
+		Type:        req.Type,
+		Required:    req.Required,
+		Options:     req.Options,
+		Pattern:     req.Pattern,
+		MaxLength:   req.MaxLength,
+		Position:    req.Position,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Question created successfully", "data": question})
+}
+
+// Get Intake Questions
+func GetIntakeQuestions(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	eventTypeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_event_type_id", "Invalid event type ID"))
+		return
+	}
+
+	questions, err := pkg.ListIntakeQuestions(db, uint(eventTypeID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"questions": questions})
+}
+
+// Delete Intake Question
+func DeleteIntakeQuestion(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	questionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_question_id", "Invalid question ID"))
+		return
+	}
+
+	if err := pkg.DeleteIntakeQuestion(db, uint(questionID)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Question deleted successfully"})
+}
+
+// Get a Booking with its intake answers
+func GetBooking(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	bookingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_booking_id", "Invalid booking ID"))
+		return
+	}
+	db = LogFields(c, db, "booking_id", bookingID)
+
+	booking, err := pkg.GetBookingByID(db, uint(bookingID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	LogFields(c, db, "interviewer_id", booking.InterviewerID)
+	if claims := auth.ClaimsFrom(c); claims != nil && claims.Role == auth.RoleInterviewer && booking.InterviewerID != claims.InterviewerID {
+		c.Error(pkg.Forbidden("not_booking_owner", "You can only view your own bookings"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"booking": booking})
+}
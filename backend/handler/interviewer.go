@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/pkg"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// InterviewerRequest registers an interviewer and where to notify them
+type InterviewerRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Email    string `json:"email" binding:"required"`
+	TimeZone string `json:"time_zone,omitempty"`
+}
+
+// Create Interviewer
+func CreateInterviewer(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	var req InterviewerRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	interviewer, err := pkg.CreateInterviewer(db, req.Name, req.Email, req.TimeZone)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Interviewer created successfully", "data": interviewer})
+}
+
+// Get Interviewers
+func GetInterviewers(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	interviewers, err := pkg.ListInterviewers(db)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"interviewers": interviewers})
+}
+
+// checkInterviewerWrite rejects changes to the slots, availability, overrides
+// or schedules of an interviewer who is not one of the organization's, and
+// interviewers changing someone else's with a Forbidden error of code.
+func checkInterviewerWrite(c *gin.Context, db *gorm.DB, interviewerID uint, code, message string) error {
+	if _, err := pkg.GetInterviewerByID(db, interviewerID); err != nil {
+		return err
+	}
+	return checkOwnInterviewer(c, interviewerID, code, message)
+}
+
+// checkOwnInterviewer rejects interviewers changing what belongs to someone else
+func checkOwnInterviewer(c *gin.Context, interviewerID uint, code, message string) error {
+	if claims := auth.ClaimsFrom(c); claims != nil && claims.Role == auth.RoleInterviewer && interviewerID != claims.InterviewerID {
+		return pkg.Forbidden(code, message)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/pkg"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RescheduleRequest moves a booking. Availability and time-slot bookings pick a
+// new slot_id; schedule bookings pick a new start and end time.
+type RescheduleRequest struct {
+	SlotID    uint      `json:"slot_id,omitempty"`
+	StartTime time.Time `json:"start_time,omitempty"`
+	EndTime   time.Time `json:"end_time,omitempty"`
+}
+
+// Get Managed Booking
+func GetManagedBooking(c *gin.Context) {
+	db, booking, ok := findManagedBooking(c)
+	if !ok {
+		return
+	}
+
+	response := gin.H{"booking": booking}
+	if booking.SlotKind == pkg.SlotKindAvailability {
+		var slot Availability
+		if err := db.First(&slot, booking.SlotID).Error; err == nil {
+			response["slot"] = slot
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Cancel Managed Booking
+func CancelManagedBooking(c *gin.Context) {
+	db, booking, ok := findManagedBooking(c)
+	if !ok {
+		return
+	}
+
+	if err := cancelBooking(db, *booking); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Booking cancelled successfully"})
+}
+
+// Reschedule Managed Booking
+func RescheduleManagedBooking(c *gin.Context) {
+	db, managed, ok := findManagedBooking(c)
+	if !ok {
+		return
+	}
+
+	var req RescheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	if managed.SlotKind != pkg.SlotKindSchedule && req.SlotID == 0 {
+		c.Error(pkg.Validation("slot_id_required", "slot_id is required"))
+		return
+	}
+
+	if managed.SlotKind != pkg.SlotKindAvailability {
+		booking, err := pkg.RescheduleBooking(db, managed.ID, req.SlotID, req.StartTime, req.EndTime)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Booking rescheduled successfully", "booking": booking})
+		return
+	}
+
+	booking, slot, err := rescheduleAvailabilityBooking(db, managed.ID, req.SlotID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Booking rescheduled successfully", "booking": booking, "slot": slot})
+}
+
+// Verify Booking
+func VerifyBooking(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	booking, err := pkg.FindBookingByVerifyToken(pkg.AllOrganizations(db), c.Param("token"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	db = LogFields(c, db, "booking_id", booking.ID, "interviewer_id", booking.InterviewerID)
+	db = pkg.WithActor(pkg.ForOrganization(db, booking.OrganizationID), "candidate:"+booking.Email)
+
+	if err := pkg.VerifyBooking(db, booking); err != nil {
+		c.Error(err)
+		return
+	}
+	slog.InfoContext(c.Request.Context(), "Booking verified")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Booking confirmed", "booking": booking})
+}
+
+// findManagedBooking looks a booking up by its manage token in any organization and
+// returns a session scoped to the booking's organization, acting as the candidate
+func findManagedBooking(c *gin.Context) (*gorm.DB, *pkg.Booking, bool) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	booking, err := pkg.FindBookingByManageToken(pkg.AllOrganizations(db), c.Param("token"))
+	if err != nil {
+		c.Error(err)
+		return nil, nil, false
+	}
+	db = LogFields(c, db, "booking_id", booking.ID, "interviewer_id", booking.InterviewerID)
+	db = pkg.WithActor(pkg.ForOrganization(db, booking.OrganizationID), "candidate:"+booking.Email)
+	return db, booking, true
+}
+
+// rescheduleAvailabilityBooking moves a booking to another open availability window
+// and offers the old window to the waitlist
+func rescheduleAvailabilityBooking(db *gorm.DB, bookingID, slotID uint) (*pkg.Booking, *Availability, error) {
+	var booking pkg.Booking
+	if err := db.First(&booking, bookingID).Error; err != nil {
+		return nil, nil, pkg.NotFound("booking_not_found", "booking not found")
+	}
+
+	var slot, previous Availability
+	if err := db.First(&slot, slotID).Error; err != nil {
+		return nil, nil, pkg.NotFound("slot_not_found", "slot not found")
+	}
+	if slot.ID == booking.SlotID {
+		return nil, nil, pkg.Conflict("already_in_slot", "booking is already in this slot")
+	}
+	if !slot.StartTime.After(time.Now()) {
+		return nil, nil, pkg.PastSlot("slot_in_past", "cannot move a booking to a past slot")
+	}
+	held, err := pkg.HeldSlotIDs(db, pkg.SlotKindAvailability)
+	if err != nil {
+		return nil, nil, err
+	}
+	if held[slot.ID] {
+		return nil, nil, pkg.Conflict("slot_held", "slot is held for a waitlisted candidate")
+	}
+	previousFound := db.First(&previous, booking.SlotID).Error == nil
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := bookAvailability(tx, slot.ID, booking.Name); err != nil {
+			return err
+		}
+		if previousFound {
+			if err := tx.Model(&previous).Updates(map[string]interface{}{"booked": false, "booked_by": ""}).Error; err != nil {
+				return errors.New("failed to release previous slot")
+			}
+		}
+
+		booking.SlotID = slot.ID
+		booking.InterviewerID = slot.InterviewerID
+		booking.StartTime = slot.StartTime
+		booking.EndTime = slot.EndTime
+		err := tx.Model(&booking).Updates(map[string]interface{}{
+			"slot_id":        booking.SlotID,
+			"interviewer_id": booking.InterviewerID,
+			"start_time":     booking.StartTime,
+			"end_time":       booking.EndTime,
+			"manage_expires": slot.EndTime,
+			"updated_at":     time.Now(),
+		}).Error
+		if err != nil {
+			return errors.New("failed to update booking")
+		}
+		return pkg.MoveBookingEvents(tx, booking.ID, 0, slot.StartTime, slot.EndTime)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if previousFound {
+		_, err := pkg.OfferFreedSlot(db, pkg.FreedSlot{
+			Kind:          pkg.SlotKindAvailability,
+			SlotID:        previous.ID,
+			InterviewerID: previous.InterviewerID,
+			EventTypeID:   booking.EventTypeID,
+			StartTime:     previous.StartTime,
+			EndTime:       previous.EndTime,
+		})
+		if err != nil {
+			slog.ErrorContext(db.Statement.Context, "Failed to offer rescheduled booking's old slot to the waitlist", "booking_id", booking.ID, "error", err)
+		}
+	}
+	return &booking, &slot, nil
+}
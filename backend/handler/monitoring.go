@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/metrics"
+	"BookingTimeSlot/backend/pkg"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// readinessTimeout bounds the database checks of one readiness probe
+const readinessTimeout = 2 * time.Second
+
+// bookingAttempts counts booking requests by outcome
+var bookingAttempts = metrics.NewCounterVec("booking_attempts_total",
+	"Booking requests by result: success, conflict, rejected or error.", "result")
+
+func init() {
+	// Export every result from the start, so rates work before the first conflict
+	for _, result := range []string{"success", "conflict", "rejected", "error"} {
+		bookingAttempts.Add(0, result)
+	}
+}
+
+// Health reports that the process is up. It touches no dependencies, so a
+// database outage does not get the server restarted.
+func Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness reports whether the server can take traffic: the database answers
+// a ping and has the tables and columns of models.
+func Readiness(db *gorm.DB, models ...interface{}) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		defer cancel()
+
+		checks := gin.H{"database": "ok", "migrations": "ok"}
+		ready := true
+		if sqlDB, err := db.DB(); err != nil {
+			checks["database"], ready = err.Error(), false
+		} else if err := sqlDB.PingContext(ctx); err != nil {
+			checks["database"], ready = err.Error(), false
+		}
+		if ready {
+			if err := pkg.CheckMigrations(db.WithContext(ctx), models...); err != nil {
+				checks["migrations"], ready = err.Error(), false
+			}
+		} else {
+			checks["migrations"] = "not checked"
+		}
+
+		status, state := http.StatusOK, "ready"
+		if !ready {
+			status, state = http.StatusServiceUnavailable, "not ready"
+		}
+		c.JSON(status, gin.H{"status": state, "checks": checks})
+	}
+}
+
+// CountBookingAttempts records the outcome of a booking route in
+// booking_attempts_total once its handler has responded.
+func CountBookingAttempts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		status := c.Writer.Status()
+		result := "rejected"
+		switch {
+		case status < 300:
+			result = "success"
+		case status == http.StatusConflict || status == http.StatusGone:
+			result = "conflict"
+		case status >= 500:
+			result = "error"
+		}
+		bookingAttempts.Inc(result)
+	}
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/pkg"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TenantBaseDomain enables subdomain tenants: with "example.com", requests to
+// acme.example.com belong to the organization with slug "acme". Empty disables it.
+var TenantBaseDomain string
+
+// OrganizationRequest creates a tenant
+type OrganizationRequest struct {
+	Name string `json:"name" binding:"required"`
+	Slug string `json:"slug" binding:"required"`
+}
+
+// ResolveOrganization picks the request's organization from the auth token, the
+// subdomain or, failing both, the default organization. It replaces "db" with a
+// session scoped to that organization, so handlers only ever see its data.
+func ResolveOrganization() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := c.MustGet("db").(*gorm.DB)
+
+		var fromHost *pkg.Organization
+		if slug := subdomain(c.Request.Host); slug != "" {
+			organization, err := pkg.FindOrganization(db, slug)
+			if err != nil {
+				c.Error(pkg.NotFound("organization_not_found", "Organization not found"))
+				c.Abort()
+				return
+			}
+			fromHost = organization
+		}
+
+		var organizationID uint
+		claims := auth.ClaimsFrom(c)
+		switch {
+		case claims != nil && claims.OrganizationID != 0:
+			if fromHost != nil && fromHost.ID != claims.OrganizationID {
+				c.Error(pkg.Forbidden("organization_mismatch", "Token does not belong to this organization"))
+				c.Abort()
+				return
+			}
+			organizationID = claims.OrganizationID
+		case fromHost != nil:
+			organizationID = fromHost.ID
+		default:
+			organization, err := pkg.FindOrganization(db, pkg.DefaultOrganizationSlug)
+			if err != nil {
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			organizationID = organization.ID
+		}
+
+		c.Set("organization_id", organizationID)
+		c.Set("db", pkg.ForOrganization(db, organizationID))
+		c.Request = c.Request.WithContext(pkg.WithOrganization(c.Request.Context(), organizationID))
+		c.Next()
+	}
+}
+
+// subdomain returns the tenant slug of a host under TenantBaseDomain, or ""
+func subdomain(host string) string {
+	if TenantBaseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	slug, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(TenantBaseDomain))
+	if !ok || slug == "" || slug == "www" || strings.Contains(slug, ".") {
+		return ""
+	}
+	return slug
+}
+
+// Create Organization
+func CreateOrganization(c *gin.Context) {
+	db := pkg.AllOrganizations(c.MustGet("db").(*gorm.DB))
+	if claims := auth.ClaimsFrom(c); claims == nil || claims.OrganizationID != 0 {
+		c.Error(pkg.Forbidden("platform_admin_required", "Only platform admins can manage organizations"))
+		return
+	}
+
+	var req OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	organization, err := pkg.CreateOrganization(db, req.Name, req.Slug)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if _, err := pkg.LoadBundledHolidays(pkg.ForOrganization(db, organization.ID)); err != nil {
+		slog.WarnContext(c.Request.Context(), "Failed to load bundled holidays", "organization", organization.Slug, "error", err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Organization created successfully", "data": organization})
+}
+
+// Get Organizations
+func GetOrganizations(c *gin.Context) {
+	db := pkg.AllOrganizations(c.MustGet("db").(*gorm.DB))
+	if claims := auth.ClaimsFrom(c); claims == nil || claims.OrganizationID != 0 {
+		c.Error(pkg.Forbidden("platform_admin_required", "Only platform admins can manage organizations"))
+		return
+	}
+
+	organizations, err := pkg.ListOrganizations(db)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organizations": organizations})
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/pkg"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AvailabilityOverlapTable is the availabilities table as a pkg.OverlapTable
+var AvailabilityOverlapTable = pkg.OverlapTable{Name: "availabilities", RangeType: "tsrange", SoftDeleted: true}
+
+// AvailabilityUpdateRequest moves an open availability window
+type AvailabilityUpdateRequest struct {
+	StartTime time.Time `json:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" binding:"required"`
+}
+
+// Update Interviewer Availability
+//
+// Moves an open window. It may not overlap another window of the interviewer;
+// with merge=true the open windows it overlaps or touches are folded into it.
+// With If-Match, the window must still have that ETag.
+func UpdateInterviewerAvailability(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	var req AvailabilityUpdateRequest
+
+	windowID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_availability_id", "Invalid availability ID"))
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	if req.StartTime.After(req.EndTime) {
+		c.Error(pkg.Validation("invalid_time_range", "Start time must be before end time"))
+		return
+	}
+
+	var availability Availability
+	if err := db.First(&availability, windowID).Error; err != nil {
+		c.Error(pkg.NotFound("availability_not_found", "Availability not found"))
+		return
+	}
+	if err := checkOwnInterviewer(c, availability.InterviewerID, "not_own_availability", "You can only change your own availability"); err != nil {
+		c.Error(err)
+		return
+	}
+	if _, err := ifMatch(c, availability.Version); err != nil {
+		c.Error(err)
+		return
+	}
+	if availability.Booked {
+		c.Error(pkg.Conflict("availability_booked", "A booked availability window cannot be changed"))
+		return
+	}
+
+	availability.StartTime, availability.EndTime = req.StartTime, req.EndTime
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := fitAvailability(tx, &availability, c.Query("merge") == "true"); err != nil {
+			return err
+		}
+		// Only the version read above may be changed, so a concurrent edit is not lost
+		result := tx.Model(&availability).Where("version = ?", availability.Version).Updates(map[string]interface{}{
+			"start_time": availability.StartTime, "end_time": availability.EndTime, "updated_at": time.Now(),
+		})
+		if result.Error != nil {
+			return pkg.OverlapConstraintError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return pkg.PreconditionFailed("version_mismatch", "Availability was changed by someone else; reload it and try again")
+		}
+		return nil
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	availability.Version++
+
+	setETag(c, availability.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Availability updated successfully", "data": availability})
+}
+
+// Get Overlap Conflicts
+//
+// Lists pairs of overlapping time slots and availability windows of the same
+// interviewer. Optional filters: kind (time_slot or availability) and interviewer_id.
+func GetOverlapConflicts(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	kind := c.Query("kind")
+	if kind != "" && kind != pkg.SlotKindTimeSlot && kind != pkg.SlotKindAvailability {
+		c.Error(pkg.Validation("invalid_kind", "Invalid kind. Use time_slot or availability."))
+		return
+	}
+	var interviewerID uint
+	if param := c.Query("interviewer_id"); param != "" {
+		id, err := strconv.Atoi(param)
+		if err != nil || id <= 0 {
+			c.Error(pkg.Validation("invalid_interviewer_id", "Invalid interviewer ID"))
+			return
+		}
+		interviewerID = uint(id)
+	}
+
+	conflicts := []pkg.OverlapConflict{}
+	if kind != pkg.SlotKindAvailability {
+		found, err := pkg.TimeSlotConflicts(db, interviewerID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		conflicts = append(conflicts, found...)
+	}
+	if kind != pkg.SlotKindTimeSlot {
+		found, err := availabilityConflicts(db, interviewerID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		conflicts = append(conflicts, found...)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"conflicts": conflicts, "total": len(conflicts)})
+}
+
+// fitAvailability rejects a window that overlaps another window of the same
+// interviewer or, with merge set, widens it over the open windows it overlaps or
+// touches, repeatedly as it grows, and deletes them. Only windows with the same
+// working days, time zone and buffer can be merged.
+func fitAvailability(tx *gorm.DB, availability *Availability, merge bool) error {
+	seen := []uint{availability.ID}
+	var merged []uint
+	for {
+		var neighbours []Availability
+		query := pkg.OverlappingWindows(tx, &Availability{}, availability.OrganizationID, availability.InterviewerID, availability.StartTime, availability.EndTime, seen, merge)
+		if err := query.Find(&neighbours).Error; err != nil {
+			return errors.New("failed to check overlapping availability")
+		}
+		if len(neighbours) == 0 {
+			break
+		}
+
+		for _, other := range neighbours {
+			if !merge {
+				return pkg.Conflict("availability_overlap", fmt.Sprintf("Overlaps availability window %d of this interviewer", other.ID))
+			}
+			seen = append(seen, other.ID)
+			overlaps := other.StartTime.Before(availability.EndTime) && other.EndTime.After(availability.StartTime)
+			if other.Booked || !sameWindowSettings(other, *availability) {
+				if overlaps {
+					return pkg.Conflict("availability_overlap", fmt.Sprintf("Overlaps availability window %d of this interviewer, which cannot be merged", other.ID))
+				}
+				continue
+			}
+			if other.StartTime.Before(availability.StartTime) {
+				availability.StartTime = other.StartTime
+			}
+			if other.EndTime.After(availability.EndTime) {
+				availability.EndTime = other.EndTime
+			}
+			merged = append(merged, other.ID)
+		}
+	}
+
+	if len(merged) > 0 {
+		if err := tx.Delete(&Availability{}, merged).Error; err != nil {
+			return errors.New("failed to delete merged availability")
+		}
+	}
+	return nil
+}
+
+// sameWindowSettings reports whether two windows differ only in their interval
+func sameWindowSettings(a, b Availability) bool {
+	return a.TimeZone == b.TimeZone && a.BufferMinutes == b.BufferMinutes && slices.Equal(a.WorkingDays, b.WorkingDays)
+}
+
+// availabilityConflicts reports overlapping availability windows, of one interviewer or, for 0, of all
+func availabilityConflicts(db *gorm.DB, interviewerID uint) ([]pkg.OverlapConflict, error) {
+	query := db.Model(&Availability{})
+	if interviewerID != 0 {
+		query = query.Where("interviewer_id = ?", interviewerID)
+	}
+	var slots []Availability
+	if err := query.Find(&slots).Error; err != nil {
+		return nil, errors.New("failed to fetch availability")
+	}
+
+	windows := make([]pkg.Window, 0, len(slots))
+	for _, slot := range slots {
+		windows = append(windows, pkg.Window{ID: slot.ID, OrganizationID: slot.OrganizationID, InterviewerID: slot.InterviewerID, StartTime: slot.StartTime, EndTime: slot.EndTime})
+	}
+	return pkg.FindOverlapConflicts(pkg.SlotKindAvailability, windows), nil
+}
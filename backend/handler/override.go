@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/pkg"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// OverrideRequest sets replacement hours for a date or marks it unavailable
+type OverrideRequest struct {
+	Date        string    `json:"date" binding:"required"`
+	Unavailable bool      `json:"unavailable"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	Reason      string    `json:"reason,omitempty"`
+}
+
+// Set Availability Override
+func SetAvailabilityOverride(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	interviewerID, err := strconv.Atoi(c.Param("interviewer_id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_interviewer_id", "Invalid interviewer ID"))
+		return
+	}
+
+	var req OverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	if err := checkInterviewerWrite(c, db, uint(interviewerID), "not_own_override", "You can only set your own overrides"); err != nil {
+		c.Error(err)
+		return
+	}
+
+	override, err := pkg.SetAvailabilityOverride(db, uint(interviewerID), req.Date, req.Unavailable, req.StartTime, req.EndTime, req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Override saved successfully", "data": override})
+}
+
+// Get Availability Overrides
+func GetAvailabilityOverrides(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	interviewerID, err := strconv.Atoi(c.Param("interviewer_id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_interviewer_id", "Invalid interviewer ID"))
+		return
+	}
+
+	overrides, err := pkg.ListAvailabilityOverrides(db, uint(interviewerID), c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"overrides": overrides})
+}
+
+// Delete Availability Override
+func DeleteAvailabilityOverride(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	overrideID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_override_id", "Invalid override ID"))
+		return
+	}
+
+	var override pkg.AvailabilityOverride
+	if err := db.First(&override, overrideID).Error; err != nil {
+		c.Error(pkg.NotFound("override_not_found", "Override not found"))
+		return
+	}
+	if err := checkOwnInterviewer(c, override.InterviewerID, "not_own_override", "You can only delete your own overrides"); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := pkg.DeleteAvailabilityOverride(db, override.ID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Override deleted successfully"})
+}
+
+// Get Holidays
+func GetHolidays(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	holidays, err := pkg.ListHolidays(db, c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"holidays": holidays})
+}
+
+// Upload a holiday calendar as CSV (date,name) or ICS
+func UploadHolidays(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	calendar := c.DefaultPostForm("calendar", pkg.DefaultHolidayCalendar)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.Error(pkg.Validation("holiday_file_required", "Holiday file is required"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(pkg.Validation("invalid_holiday_file", "Failed to read holiday file"))
+		return
+	}
+	defer file.Close()
+
+	var holidays []pkg.Holiday
+	switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+	case ".ics", ".ical":
+		holidays, err = pkg.ParseHolidaysICS(file)
+	case ".csv":
+		holidays, err = pkg.ParseHolidaysCSV(file)
+	default:
+		c.Error(pkg.Validation("unsupported_file_type", "Unsupported file type. Use .csv or .ics"))
+		return
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	count, err := pkg.SaveHolidays(db, calendar, holidays)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Holidays imported successfully", "calendar": calendar, "count": count})
+}
+
+// applyDateRule restricts an interviewer's availability for one date to the
+// holiday, blackout and replacement-hours rules that apply to it.
+func applyDateRule(rule *pkg.DateRule, interviewerID uint, slots []Availability) []Availability {
+	if rule.Blocked {
+		return []Availability{}
+	}
+	if rule.Override == nil {
+		return slots
+	}
+
+	// Replacement hours stand in for the stored windows of the day
+	window := rule.Override
+	return []Availability{{
+		InterviewerID: interviewerID,
+		StartTime:     window.StartTime,
+		EndTime:       window.EndTime,
+		WorkingDays:   []int{int(window.StartTime.Weekday())},
+	}}
+}
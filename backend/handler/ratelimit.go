@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/metrics"
+	"BookingTimeSlot/backend/pkg"
+	"BookingTimeSlot/backend/ratelimit"
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChallengeHeader carries the public booking page's answer to the challenge
+const ChallengeHeader = "X-Booking-Challenge"
+
+// ChallengeVerifier checks a proof-of-work solution or CAPTCHA response sent
+// with a public booking request, returning an error when it is missing or wrong
+type ChallengeVerifier interface {
+	Verify(ctx context.Context, response, clientIP string) error
+}
+
+// Abuse protection of the public booking routes; main sets the limiters from
+// the configuration. Nil limiters and a nil challenge allow every request.
+var (
+	BookingIPLimiter          *ratelimit.Limiter
+	BookingEmailLimiter       *ratelimit.Limiter
+	BookingInterviewerLimiter *ratelimit.Limiter
+	BookingChallenge          ChallengeVerifier
+)
+
+// bookingsRateLimited counts booking requests rejected by each limit
+var bookingsRateLimited = metrics.NewCounterVec("booking_rate_limited_total",
+	"Booking requests rejected by a rate limit: ip, email or interviewer.", "limit")
+
+// ProtectBooking rejects clients that send booking requests too often and, when
+// a challenge is configured, requests without a valid challenge response
+func ProtectBooking() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := takeBookingToken(c, BookingIPLimiter, c.ClientIP()); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if BookingChallenge != nil {
+			if err := BookingChallenge.Verify(c.Request.Context(), c.GetHeader(ChallengeHeader), c.ClientIP()); err != nil {
+				slog.InfoContext(c.Request.Context(), "Booking challenge failed", "error", err)
+				c.Error(pkg.Forbidden("challenge_failed", "The booking challenge was not solved"))
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// LimitBooking applies the per-email and per-interviewer limits, once a booking
+// handler knows who is booking whom
+func LimitBooking(c *gin.Context, email string, interviewerID uint) error {
+	if err := takeBookingToken(c, BookingEmailLimiter, strings.ToLower(strings.TrimSpace(email))); err != nil {
+		return err
+	}
+	if interviewerID == 0 {
+		return nil
+	}
+	return takeBookingToken(c, BookingInterviewerLimiter, strconv.FormatUint(uint64(interviewerID), 10))
+}
+
+// takeBookingToken takes a token for key, setting Retry-After when there is
+// none. A failing store lets the request through rather than block bookings.
+func takeBookingToken(c *gin.Context, limiter *ratelimit.Limiter, key string) error {
+	allowed, wait, err := limiter.Allow(c.Request.Context(), key)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Rate limit check failed, allowing request", "limit", limiter.Name, "error", err)
+		return nil
+	}
+	if allowed {
+		return nil
+	}
+
+	bookingsRateLimited.Inc(limiter.Name)
+	slog.InfoContext(c.Request.Context(), "Booking rate limited", "limit", limiter.Name)
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	return pkg.RateLimited("too_many_bookings", "Too many booking requests; try again in "+(time.Duration(seconds)*time.Second).String())
+}
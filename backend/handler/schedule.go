@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ScheduleTemplateRequest defines recurring weekday hours in the interviewer's time zone
+type ScheduleTemplateRequest struct {
+	Weekday     *int   `json:"weekday" binding:"required"`
+	StartTime   string `json:"start_time" binding:"required"` // HH:MM
+	EndTime     string `json:"end_time" binding:"required"`   // HH:MM
+	StepMinutes int    `json:"step_minutes" binding:"required"`
+	TimeZone    string `json:"time_zone,omitempty"`
+}
+
+// Create Schedule Template
+func CreateScheduleTemplate(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	interviewerID, err := strconv.Atoi(c.Param("interviewer_id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_interviewer_id", "Invalid interviewer ID"))
+		return
+	}
+
+	var req ScheduleTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	if err := checkInterviewerWrite(c, db, uint(interviewerID), "not_own_schedule", "You can only set your own schedule"); err != nil {
+		c.Error(err)
+		return
+	}
+
+	template, err := pkg.SaveScheduleTemplate(db, uint(interviewerID), *req.Weekday, req.StartTime, req.EndTime, req.StepMinutes, req.TimeZone)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Schedule template saved successfully", "data": template})
+}
+
+// Get Schedule Templates
+func GetScheduleTemplates(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	interviewerID, err := strconv.Atoi(c.Param("interviewer_id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_interviewer_id", "Invalid interviewer ID"))
+		return
+	}
+
+	templates, err := pkg.ListScheduleTemplates(db, uint(interviewerID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// Delete Schedule Template
+func DeleteScheduleTemplate(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_template_id", "Invalid template ID"))
+		return
+	}
+
+	var template pkg.ScheduleTemplate
+	if err := db.First(&template, templateID).Error; err != nil {
+		c.Error(pkg.NotFound("schedule_template_not_found", "Schedule template not found"))
+		return
+	}
+	if err := checkOwnInterviewer(c, template.InterviewerID, "not_own_schedule", "You can only delete your own schedule"); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := pkg.DeleteScheduleTemplate(db, template.ID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule template deleted successfully"})
+}
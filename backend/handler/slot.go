@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"BookingTimeSlot/backend/pkg"
 	"database/sql"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 var sqlDB *sql.DB // Database instance
@@ -27,23 +31,24 @@ func GetAvailableSlots(c *gin.Context) {
 	date := c.Param("date")
 
 	if sqlDB == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database not connected"})
+		c.Error(errors.New("database not connected"))
 		return
 	}
 
 	parsedDate, err := time.Parse("2006-01-02", date)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD."})
+		c.Error(pkg.Validation("invalid_date", "Invalid date format. Use YYYY-MM-DD."))
 		return
 	}
 
-	startDate := time.Date(parsedDate.Year(), parsedDate.Month(), 1, 0, 0, 0, 0, time.UTC)
-	endDate := startDate.AddDate(0, 1, -1)
+	// Only the requested day; use /api/availability/range for multi-day queries
+	day := time.Date(parsedDate.Year(), parsedDate.Month(), parsedDate.Day(), 0, 0, 0, 0, time.UTC)
 
-	query := `SELECT slot_id, start_time, end_time, available_date, user_id, booked, booked_time FROM slots WHERE available_date BETWEEN $1 AND $2 AND interviewer_id = $3 AND slot_id NOT IN (SELECT time_slot FROM bookings WHERE booking_date BETWEEN $1 AND $2)`
-	rows, err := sqlDB.Query(query, startDate, endDate, interviewerID)
+	// Plain SQL is not tenant scoped; filter by the request's organization explicitly
+	query := `SELECT slot_id, start_time, end_time, available_date, user_id, booked, booked_time FROM slots WHERE available_date = $1 AND interviewer_id = $2 AND organization_id = $3 AND slot_id NOT IN (SELECT time_slot FROM bookings WHERE booking_date = $1 AND organization_id = $3)`
+	rows, err := sqlDB.Query(query, day, interviewerID, c.GetUint("organization_id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database query failed"})
+		c.Error(err)
 		return
 	}
 	defer rows.Close()
@@ -52,16 +57,12 @@ func GetAvailableSlots(c *gin.Context) {
 	for rows.Next() {
 		var slot Slot
 		if err := rows.Scan(&slot.SlotID, &slot.StartTime, &slot.EndTime, &slot.AvailableDate, &slot.UserID, &slot.Booked, &slot.BookedTime); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan slots"})
+			c.Error(err)
 			return
 		}
 		slots = append(slots, slot)
 	}
 
-	if len(slots) == 0 {
-		c.JSON(http.StatusOK, gin.H{"message": "No available slots found"})
-		return
-	}
 	c.JSON(http.StatusOK, gin.H{"available_slots": slots})
 }
 
@@ -79,26 +80,54 @@ func BookSlot(c *gin.Context) {
 
 	var req BookingRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
-	var slotExists bool
-	existQuery := `SELECT EXISTS(SELECT 1 FROM bookings WHERE time_slot = $1 AND booking_date = CURRENT_DATE AND interviewer_id = $2)`
-	if err := sqlDB.QueryRow(existQuery, req.TimeSlot, req.InterviewerID).Scan(&slotExists); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check slot availability"})
+	if err := LimitBooking(c, req.Email, uint(req.InterviewerID)); err != nil {
+		c.Error(err)
 		return
 	}
 
-	if slotExists {
-		c.JSON(http.StatusConflict, gin.H{"message": "Slot already booked"})
+	// The slot is "15:04 - 15:04" today, or an hour from "15:04"
+	db := c.MustGet("db").(*gorm.DB)
+	today := time.Now().UTC().Format("2006-01-02")
+	from, to, ranged := strings.Cut(req.TimeSlot, " - ")
+	start, err := time.Parse("2006-01-02 15:04", today+" "+from)
+	if err != nil {
+		c.Error(pkg.Validation("invalid_time_slot", "time_slot must be HH:MM or HH:MM - HH:MM"))
 		return
 	}
+	end := start.Add(time.Hour)
+	if ranged {
+		if end, err = time.Parse("2006-01-02 15:04", today+" "+to); err != nil || !end.After(start) {
+			c.Error(pkg.Validation("invalid_time_slot", "time_slot must be HH:MM or HH:MM - HH:MM"))
+			return
+		}
+	}
 
-	insertQuery := `INSERT INTO bookings (user_id, slot_date, slot_time, email, status, created_at, updated_at, slot_id, booked_by, booked_at, start_time, end_time, name, interviewer_id, time_slot, booking_date) VALUES ($1, CURRENT_DATE, $2, $3, 'booked', NOW(), NOW(), $4, $5, NOW(), CURRENT_TIME, CURRENT_TIME + INTERVAL '1 hour', $6, $7, $8, CURRENT_DATE)`
-	_, err := sqlDB.Exec(insertQuery, req.UserID, req.TimeSlot, req.Email, req.SlotID, req.BookedBy, req.Name, req.InterviewerID, req.TimeSlot)
+	booking := pkg.Booking{
+		Name:          req.Name,
+		Email:         req.Email,
+		SlotID:        uint(req.SlotID),
+		SlotKind:      pkg.SlotKindSchedule,
+		UserID:        req.UserID,
+		InterviewerID: uint(req.InterviewerID),
+		StartTime:     start,
+		EndTime:       end,
+	}
+	err = pkg.PlaceBooking(db, &booking, func(tx *gorm.DB) error {
+		overlapping, err := pkg.HasOverlappingBooking(tx, booking.InterviewerID, booking.StartTime, booking.EndTime)
+		if err != nil {
+			return err
+		}
+		if overlapping {
+			return pkg.Conflict("slot_already_booked", "Slot already booked")
+		}
+		return nil
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to book slot"})
+		c.Error(err)
 		return
 	}
 
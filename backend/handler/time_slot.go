@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/pkg"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TimeSlotRequest creates a stored, individually bookable slot
+type TimeSlotRequest struct {
+	InterviewerID uint      `json:"interviewer_id" binding:"required"`
+	StartTime     time.Time `json:"start_time" binding:"required"`
+	EndTime       time.Time `json:"end_time" binding:"required"`
+}
+
+// TimeSlotUpdateRequest moves an open time slot
+type TimeSlotUpdateRequest struct {
+	StartTime time.Time `json:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" binding:"required"`
+}
+
+// Create Time Slot
+//
+// A slot may not overlap another slot of the interviewer. With merge=true the
+// open slots it overlaps or touches are folded into it instead. Interviewers
+// can only create their own slots.
+func CreateTimeSlot(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	var req TimeSlotRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	if err := checkInterviewerWrite(c, db, req.InterviewerID, "not_own_slot", "You can only create your own slots"); err != nil {
+		c.Error(err)
+		return
+	}
+
+	slot, err := pkg.CreateTimeSlot(db, req.InterviewerID, req.StartTime, req.EndTime, c.Query("merge") == "true")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Time slot created successfully", "data": slot})
+}
+
+// Update Time Slot
+//
+// Moves an open slot, with the same overlap rules and merge option as creating one.
+// Interviewers can only move their own slots. With If-Match, the slot must
+// still have that ETag.
+func UpdateTimeSlot(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	var req TimeSlotUpdateRequest
+
+	slotID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_slot_id", "Invalid slot ID"))
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	var existing pkg.TimeSlot
+	if err := db.First(&existing, slotID).Error; err != nil {
+		c.Error(pkg.NotFound("slot_not_found", "Time slot not found"))
+		return
+	}
+	if err := checkOwnInterviewer(c, existing.InterviewerID, "not_own_slot", "You can only change your own slots"); err != nil {
+		c.Error(err)
+		return
+	}
+	ifVersion, err := ifMatch(c, existing.Version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	slot, err := pkg.UpdateTimeSlot(db, existing.ID, req.StartTime, req.EndTime, c.Query("merge") == "true", ifVersion)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, slot.Version)
+	c.JSON(http.StatusOK, gin.H{"message": "Time slot updated successfully", "data": slot})
+}
+
+// Get Time Slot
+func GetTimeSlot(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	slotID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_slot_id", "Invalid slot ID"))
+		return
+	}
+
+	var slot pkg.TimeSlot
+	if err := db.First(&slot, slotID).Error; err != nil {
+		c.Error(pkg.NotFound("slot_not_found", "Time slot not found"))
+		return
+	}
+
+	setETag(c, slot.Version)
+	c.JSON(http.StatusOK, gin.H{"slot": slot})
+}
+
+// Get open Time Slots for a date, optionally for one interviewer
+func GetTimeSlots(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	date := c.Query("date")
+	if date == "" {
+		c.Error(pkg.Validation("date_required", "Date is required"))
+		return
+	}
+
+	var interviewerID uint
+	if param := c.Query("interviewer_id"); param != "" {
+		id, err := strconv.Atoi(param)
+		if err != nil || id <= 0 {
+			c.Error(pkg.Validation("invalid_interviewer_id", "Invalid interviewer ID"))
+			return
+		}
+		interviewerID = uint(id)
+	}
+
+	slots, err := pkg.FetchAvailabilityForInterviewer(db, interviewerID, date)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"slots": slots})
+}
+
+// maxSlotImportBytes bounds the size of an uploaded slot import
+const maxSlotImportBytes = 10 << 20
+
+// Import Time Slots
+//
+// The body is CSV (text/csv) or JSON, or a multipart "file" field named *.csv or
+// *.json. Rows are only stored with commit=true, and only if every row is valid;
+// otherwise the per-row report is a dry run. Interviewers can only import their
+// own slots.
+func ImportTimeSlots(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	commit := c.Query("commit") == "true"
+
+	rows, err := readSlotImport(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if claims := auth.ClaimsFrom(c); claims != nil && claims.Role == auth.RoleInterviewer {
+		for i := range rows {
+			if rows[i].Status != pkg.SlotImportInvalid && rows[i].InterviewerID != claims.InterviewerID {
+				rows[i].Status, rows[i].Code, rows[i].Error = pkg.SlotImportInvalid, "not_own_slot", "you can only import your own slots"
+			}
+		}
+	}
+
+	report, err := pkg.ImportTimeSlots(db, rows, commit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	status := http.StatusOK
+	if report.Committed {
+		status = http.StatusCreated
+	}
+	c.JSON(status, report)
+}
+
+// readSlotImport parses the import from an uploaded file or the request body
+func readSlotImport(c *gin.Context) ([]pkg.SlotImportRow, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSlotImportBytes)
+
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, pkg.Validation("slot_file_required", "Slot file is required")
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, pkg.Validation("invalid_slot_file", "Failed to read slot file")
+		}
+		defer file.Close()
+
+		switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+		case ".csv":
+			return pkg.ParseSlotImportCSV(file)
+		case ".json":
+			return pkg.ParseSlotImportJSON(file)
+		}
+		return nil, pkg.Validation("unsupported_file_type", "Unsupported file type. Use .csv or .json")
+	}
+
+	if c.ContentType() == "text/csv" {
+		return pkg.ParseSlotImportCSV(c.Request.Body)
+	}
+	return pkg.ParseSlotImportJSON(c.Request.Body)
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/pkg"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WaitlistRequest joins the waitlist for an interviewer, event type and/or date range
+type WaitlistRequest struct {
+	Name          string `json:"name" binding:"required"`
+	Email         string `json:"email" binding:"required"`
+	InterviewerID uint   `json:"interviewer_id,omitempty"`
+	EventTypeID   uint   `json:"event_type_id,omitempty"`
+	FromDate      string `json:"from_date,omitempty"`
+	ToDate        string `json:"to_date,omitempty"`
+}
+
+// Join Waitlist
+func JoinWaitlist(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	var req WaitlistRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	entry, token, err := pkg.JoinWaitlist(db, req.Name, req.Email, req.InterviewerID, req.EventTypeID, req.FromDate, req.ToDate)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The leave token is only shown here; the candidate needs it to leave again
+	c.JSON(http.StatusCreated, gin.H{"message": "Joined waitlist successfully", "data": entry, "leave_token": token})
+}
+
+// Leave Waitlist
+//
+// Candidates pass the leave_token they got when joining as the token query
+// parameter; staff may remove any entry.
+func LeaveWaitlist(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	entryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(pkg.Validation("invalid_waitlist_entry_id", "Invalid waitlist entry ID"))
+		return
+	}
+	if claims := auth.ClaimsFrom(c); claims == nil || claims.Role != auth.RoleAdmin && claims.Role != auth.RoleInterviewer {
+		if err := pkg.CheckLeaveToken(db, uint(entryID), c.Query("token")); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	if err := pkg.LeaveWaitlist(db, uint(entryID)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left waitlist successfully"})
+}
+
+// Get Waitlist Offer by claim token
+func GetWaitlistOffer(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	// Claim tokens are unique across organizations
+	offer, entry, err := pkg.FindPendingOffer(pkg.AllOrganizations(db), c.Param("token"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"offer": offer, "name": entry.Name})
+}
+
+// Claim Waitlist Offer
+func ClaimWaitlistOffer(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	offer, entry, err := pkg.FindPendingOffer(pkg.AllOrganizations(db), c.Param("token"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	db = LogFields(c, db, "waitlist_offer_id", offer.ID, "interviewer_id", offer.InterviewerID)
+	db = pkg.WithActor(pkg.ForOrganization(db, offer.OrganizationID), "candidate:"+entry.Email)
+	if err := LimitBooking(c, entry.Email, offer.InterviewerID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Availability windows live in this package, so mark them booked here
+	var reserve func(tx *gorm.DB) error
+	if offer.SlotKind == pkg.SlotKindAvailability {
+		reserve = func(tx *gorm.DB) error {
+			return bookAvailability(tx, offer.SlotID, entry.Name)
+		}
+	}
+	booking, err := pkg.BookOfferedSlot(db, offer, entry, reserve)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	db = LogFields(c, db, "booking_id", booking.ID)
+	slog.InfoContext(c.Request.Context(), "Waitlist offer claimed")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Booking successful", "booking": booking})
+}
+
+// withoutHeldSlots hides availability windows reserved for waitlisted candidates
+func withoutHeldSlots(db *gorm.DB, slots []Availability) ([]Availability, error) {
+	held, err := pkg.HeldSlotIDs(db, pkg.SlotKindAvailability)
+	if err != nil {
+		return nil, err
+	}
+	if len(held) == 0 {
+		return slots, nil
+	}
+
+	open := []Availability{}
+	for _, slot := range slots {
+		if !held[slot.ID] {
+			open = append(open, slot)
+		}
+	}
+	return open, nil
+}
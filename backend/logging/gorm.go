@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"BookingTimeSlot/backend/config"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// QueryLogger logs GORM queries through slog with the fields of the query's
+// context, so queries run for a request carry its request ID.
+type QueryLogger struct {
+	Level         gormlogger.LogLevel
+	SlowThreshold time.Duration // 0 disables slow query logging
+}
+
+// NewQueryLogger logs at the database's log level: at warn, failed and slow
+// queries are logged; at info, every query is.
+func NewQueryLogger(cfg config.Database) *QueryLogger {
+	levels := map[string]gormlogger.LogLevel{
+		"silent": gormlogger.Silent,
+		"error":  gormlogger.Error,
+		"warn":   gormlogger.Warn,
+		"info":   gormlogger.Info,
+	}
+	level, ok := levels[strings.ToLower(cfg.LogLevel)]
+	if !ok {
+		level = gormlogger.Warn
+	}
+	return &QueryLogger{Level: level, SlowThreshold: cfg.SlowQueryThreshold()}
+}
+
+// LogMode returns a copy logging at level
+func (l *QueryLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.Level = level
+	return &copied
+}
+
+func (l *QueryLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.Level >= gormlogger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *QueryLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.Level >= gormlogger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *QueryLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.Level >= gormlogger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace logs a finished query: failures at error level (not found is a normal
+// outcome and skipped), queries slower than SlowThreshold at warn and, at the
+// info level, every other query at info.
+func (l *QueryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.Level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+
+	var level slog.Level
+	var msg string
+	switch {
+	case err != nil && l.Level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		level, msg = slog.LevelError, "Query failed"
+	case l.SlowThreshold > 0 && elapsed > l.SlowThreshold && l.Level >= gormlogger.Warn:
+		level, msg = slog.LevelWarn, "Slow query"
+	case l.Level >= gormlogger.Info:
+		level, msg = slog.LevelInfo, "Query"
+	default:
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	slog.Default().LogAttrs(ctx, level, msg, attrs...)
+}
@@ -0,0 +1,96 @@
+// Package logging sets up the structured process logger and carries log
+// fields such as the request ID through contexts, so every line logged for a
+// request, including its database queries, can be correlated.
+package logging
+
+import (
+	"BookingTimeSlot/backend/config"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// -------------------- Setup --------------------
+
+// Setup installs the default logger at the configured level and format. Output
+// of the standard log package goes through it too.
+func Setup(cfg config.Logging) {
+	level, err := ParseLevel(cfg.Level)
+	slog.SetDefault(New(os.Stderr, level, cfg.Format))
+	if err != nil {
+		slog.Warn("Invalid log level, using info", "value", cfg.Level)
+	}
+}
+
+// New returns a logger writing JSON, or text when format is "text", at level
+// and above. Fields attached to a context with With are added to every record
+// logged with that context.
+func New(w io.Writer, level slog.Level, format string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler = slog.NewJSONHandler(w, options)
+	if format == "text" {
+		handler = slog.NewTextHandler(w, options)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+// ParseLevel reads debug, info, warn or error; empty is info
+func ParseLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(value) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q", value)
+}
+
+// Fatal logs msg at error level and exits
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// -------------------- Context Fields --------------------
+
+// fieldsKey stores the log fields of a context
+type fieldsKey struct{}
+
+// With returns a context whose log records carry the given key-value pairs in
+// addition to those already attached, e.g. With(ctx, "booking_id", id).
+func With(ctx context.Context, args ...any) context.Context {
+	current, _ := ctx.Value(fieldsKey{}).([]slog.Attr)
+	fields := make([]slog.Attr, 0, len(current)+len(args)/2)
+	fields = append(fields, current...)
+	fields = append(fields, slog.Group("", args...).Value.Group()...)
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+// contextHandler adds the fields attached to a record's context
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if fields, ok := ctx.Value(fieldsKey{}).([]slog.Attr); ok {
+			record.AddAttrs(fields...)
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,308 @@
+// Package metrics keeps process metrics in memory and serves them in the
+// Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultBuckets are the latency histogram bounds in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// collector is a metric family that can write itself
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// registry holds every registered metric family, written in name order
+var registry = struct {
+	sync.Mutex
+	collectors map[string]collector
+}{collectors: map[string]collector{}}
+
+func register(c collector) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, exists := registry.collectors[c.name()]; exists {
+		panic("metrics: " + c.name() + " registered twice")
+	}
+	registry.collectors[c.name()] = c
+}
+
+// -------------------- Counters --------------------
+
+// CounterVec is a counter per combination of label values.
+type CounterVec struct {
+	family
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounterVec registers a counter family, e.g. NewCounterVec("jobs_total", "Jobs run.", "result").
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{family: family{metricName: name, help: help, kind: "counter", labels: labels}, values: map[string]float64{}}
+	register(c)
+	return c
+}
+
+// Inc adds one to the counter of the given label values.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta to the counter of the given label values.
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	key := c.key(labelValues)
+	c.mu.Lock()
+	c.values[key] += delta
+	c.mu.Unlock()
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.header(w)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.metricName, c.labelText(key, ""), formatValue(c.values[key]))
+	}
+}
+
+// -------------------- Gauges --------------------
+
+// GaugeFunc is a gauge read when metrics are scraped.
+type GaugeFunc struct {
+	family
+	read func() float64
+}
+
+// NewGaugeFunc registers a gauge whose value read returns at scrape time.
+func NewGaugeFunc(name, help string, read func() float64) *GaugeFunc {
+	g := &GaugeFunc{family: family{metricName: name, help: help, kind: "gauge"}, read: read}
+	register(g)
+	return g
+}
+
+// NewCounterFunc registers a counter whose running total read returns at scrape time.
+func NewCounterFunc(name, help string, read func() float64) *GaugeFunc {
+	g := &GaugeFunc{family: family{metricName: name, help: help, kind: "counter"}, read: read}
+	register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w *bufio.Writer) {
+	g.header(w)
+	fmt.Fprintf(w, "%s %s\n", g.metricName, formatValue(g.read()))
+}
+
+// -------------------- Histograms --------------------
+
+// HistogramVec is a histogram per combination of label values.
+type HistogramVec struct {
+	family
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogram
+}
+
+// histogram holds the observations of one label combination
+type histogram struct {
+	counts []uint64 // per bucket, made cumulative when written
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec registers a histogram family with the given upper bucket bounds.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{family: family{metricName: name, help: help, kind: "histogram", labels: labels}, buckets: buckets, values: map[string]*histogram{}}
+	register(h)
+	return h
+}
+
+// Observe records one value for the given label values.
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := h.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entry := h.values[key]
+	if entry == nil {
+		entry = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.values[key] = entry
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			entry.counts[i]++
+			break
+		}
+	}
+	entry.count++
+	entry.sum += value
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.header(w)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.values) {
+		entry := h.values[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += entry.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, h.labelText(key, formatValue(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, h.labelText(key, "+Inf"), entry.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.metricName, h.labelText(key, ""), formatValue(entry.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.metricName, h.labelText(key, ""), entry.count)
+	}
+}
+
+// -------------------- Exposition --------------------
+
+// Handler serves every registered metric in the Prometheus text format.
+func Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		registry.Lock()
+		collectors := make([]collector, 0, len(registry.collectors))
+		for _, collector := range registry.collectors {
+			collectors = append(collectors, collector)
+		}
+		registry.Unlock()
+		sort.Slice(collectors, func(i, j int) bool { return collectors[i].name() < collectors[j].name() })
+
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Status(http.StatusOK)
+		w := bufio.NewWriter(c.Writer)
+		for _, collector := range collectors {
+			collector.write(w)
+		}
+		w.Flush()
+	}
+}
+
+// family is the name, help text and labels shared by every metric kind
+type family struct {
+	metricName string
+	help       string
+	kind       string
+	labels     []string
+}
+
+func (f *family) name() string {
+	return f.metricName
+}
+
+func (f *family) header(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.metricName, escapeHelp(f.help), f.metricName, f.kind)
+}
+
+// key joins label values into a map key; missing values are empty
+func (f *family) key(labelValues []string) string {
+	values := make([]string, len(f.labels))
+	copy(values, labelValues)
+	return strings.Join(values, "\xff")
+}
+
+// labelText renders {name="value",...} for a key, plus le for histogram buckets
+func (f *family) labelText(key, le string) string {
+	var pairs []string
+	if len(f.labels) > 0 {
+		for i, value := range strings.Split(key, "\xff") {
+			pairs = append(pairs, f.labels[i]+`="`+escapeLabel(value)+`"`)
+		}
+	}
+	if le != "" {
+		pairs = append(pairs, `le="`+le+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+// -------------------- HTTP Metrics --------------------
+
+var (
+	httpRequests = NewCounterVec("http_requests_total",
+		"HTTP requests by method, route and status code.", "method", "route", "status")
+	httpDuration = NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency by method and route.", DefaultBuckets, "method", "route")
+)
+
+// Middleware counts and times every request by its route pattern, so paths
+// with IDs share one series. Requests matching no route count as "unmatched".
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		httpRequests.Inc(method, route, strconv.Itoa(c.Writer.Status()))
+		httpDuration.Observe(time.Since(start).Seconds(), method, route)
+	}
+}
+
+// -------------------- Database Metrics --------------------
+
+// RegisterDBStats exposes the connection pool statistics of db.
+func RegisterDBStats(db *sql.DB) {
+	NewGaugeFunc("db_open_connections", "Open database connections, in use or idle.", func() float64 {
+		return float64(db.Stats().OpenConnections)
+	})
+	NewGaugeFunc("db_in_use_connections", "Database connections currently in use.", func() float64 {
+		return float64(db.Stats().InUse)
+	})
+	NewGaugeFunc("db_idle_connections", "Idle database connections.", func() float64 {
+		return float64(db.Stats().Idle)
+	})
+	NewGaugeFunc("db_max_open_connections", "Maximum number of open database connections; 0 is unlimited.", func() float64 {
+		return float64(db.Stats().MaxOpenConnections)
+	})
+	NewCounterFunc("db_wait_count_total", "Connections waited for because the pool was exhausted.", func() float64 {
+		return float64(db.Stats().WaitCount)
+	})
+	NewCounterFunc("db_wait_duration_seconds_total", "Time spent waiting for a free connection.", func() float64 {
+		return db.Stats().WaitDuration.Seconds()
+	})
+}
@@ -1,39 +1,52 @@
-package main
+package notifications
 
 import (
+	"BookingTimeSlot/backend/config"
+	"BookingTimeSlot/backend/logging"
+	"BookingTimeSlot/backend/tracing"
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/smtp"
-	"os"
 
-	"github.com/joho/godotenv"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// sendEmail sends an email using SMTP
-func sendEmail(recipient, subject, body string) error {
-	// Load environment variables from .env file
-	if err := godotenv.Load(); err != nil {
-		log.Printf("Warning: No .env file found, using system environment variables.")
-	}
+// Message is an email waiting to be delivered by the queue worker
+type Message struct {
+	Recipient string
+	Subject   string
+	Body      string
+
+	// queuedBy is the span that queued the message, the parent of its send span
+	queuedBy trace.SpanContext
+}
+
+// Server is the mail server emails are sent through; main sets it from the
+// configuration. Without credentials, sending fails and the email is logged.
+var Server config.SMTP
 
-	// Get email credentials from environment variables
-	email := os.Getenv("EMAIL")
-	password := os.Getenv("EMAIL_PASSWORD")
+// queue buffers outgoing emails so request handlers never wait on SMTP
+var queue = make(chan Message, 256)
+
+// SendEmail sends an email using SMTP, recorded as a span of the trace in ctx
+func SendEmail(ctx context.Context, recipient, subject, body string) (err error) {
+	// Recipients and subjects name candidates, so the span only records the server
+	ctx, span := tracing.Tracer().Start(ctx, "smtp send",
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(semconv.ServerAddress(Server.Host)))
+	defer func() { tracing.End(span, err) }()
 
 	// Validate that credentials are set
-	if email == "" || password == "" {
-		return fmt.Errorf("email or password not set in environment variables")
+	if !Server.Configured() {
+		return fmt.Errorf("SMTP credentials are not configured")
 	}
 
-	// SMTP server details (Gmail in this case)
-	smtpHost := "smtp.gmail.com"
-	smtpPort := "587"
-
 	// Set up authentication using PlainAuth
-	auth := smtp.PlainAuth("", email, password, smtpHost)
+	auth := smtp.PlainAuth("", Server.Username, Server.Password, Server.Host)
 
 	// Construct the email message
-	msg := "From: " + email + "\r\n" +
+	msg := "From: " + Server.Sender() + "\r\n" +
 		"To: " + recipient + "\r\n" +
 		"Subject: " + subject + "\r\n" +
 		"MIME-version: 1.0;\r\n" +
@@ -41,25 +54,61 @@ func sendEmail(recipient, subject, body string) error {
 		body + "\r\n"
 
 	// Send the email
-	err := smtp.SendMail(smtpHost+":"+smtpPort, auth, email, []string{recipient}, []byte(msg))
+	err = smtp.SendMail(Server.Address(), auth, Server.Sender(), []string{recipient}, []byte(msg))
 	if err != nil {
 		return fmt.Errorf("error sending email: %v", err)
 	}
 
-	log.Println("Email sent successfully to:", recipient)
+	slog.InfoContext(ctx, "Email sent", "recipient", recipient)
 	return nil
 }
 
-func main() {
-	// Example: Sending an email
-	recipient := "recipient@example.com"
-	subject := "Subject of the email"
-	body := "Body of the email"
+// Enqueue schedules an email for delivery by the worker. Its send span joins the
+// trace in ctx. When the queue is full the message is dropped and logged rather
+// than blocking the caller.
+func Enqueue(ctx context.Context, recipient, subject, body string) {
+	span := trace.SpanFromContext(ctx)
+	select {
+	case queue <- Message{Recipient: recipient, Subject: subject, Body: body, queuedBy: span.SpanContext()}:
+		span.AddEvent("email queued")
+	default:
+		span.AddEvent("email dropped")
+		slog.ErrorContext(ctx, "Notification queue full, dropping email", "recipient", recipient, "subject", subject)
+	}
+}
 
-	err := sendEmail(recipient, subject, body)
-	if err != nil {
-		log.Fatalf("Failed to send email: %v", err)
-	} else {
-		fmt.Println("Email sent successfully!")
+// QueueDepth reports how many emails are waiting to be sent
+func QueueDepth() int {
+	return len(queue)
+}
+
+// RunWorker delivers queued emails until the context is cancelled, then
+// delivers those already queued and returns
+func RunWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case msg := <-queue:
+					deliver(context.WithoutCancel(ctx), msg)
+				default:
+					return
+				}
+			}
+		case msg := <-queue:
+			deliver(ctx, msg)
+		}
+	}
+}
+
+// deliver sends a queued email as part of the trace that queued it
+func deliver(ctx context.Context, msg Message) {
+	ctx = trace.ContextWithSpanContext(ctx, msg.queuedBy)
+	if msg.queuedBy.IsValid() {
+		ctx = logging.With(ctx, "trace_id", msg.queuedBy.TraceID().String())
+	}
+	if err := SendEmail(ctx, msg.Recipient, msg.Subject, msg.Body); err != nil {
+		slog.ErrorContext(ctx, "Failed to send email", "recipient", msg.Recipient, "error", err)
 	}
 }
@@ -0,0 +1,372 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Spec describes the API. The OpenAPI 3 document is generated from it, with
+// schemas reflected from the Go types handlers actually bind and return.
+type Spec struct {
+	Title      string
+	Version    string
+	Error      interface{} // body of every error response, e.g. handler.Problem{}
+	Operations []Operation
+
+	once     sync.Once
+	document []byte
+}
+
+// Operation documents one route. Path uses gin syntax, e.g. "/api/bookings/:id".
+type Operation struct {
+	Method   string
+	Path     string
+	Summary  string
+	Tag      string
+	Roles    []string    // roles allowed to call it; empty means public
+	Query    []Parameter // query parameters
+	Request  interface{} // JSON body, e.g. handler.BookingRequest{}; nil for none
+	Form     Object      // multipart form body, for uploads
+	Status   int         // success status; defaults to 200
+	Response interface{} // success body, e.g. Object{"data": pkg.Interviewer{}}
+	Produces []string    // non-JSON success media types, e.g. "text/csv"; used instead of Response
+
+	Deprecated bool // kept for old clients; a successor should be used instead
+}
+
+// Parameter is a query parameter.
+type Parameter struct {
+	Name        string
+	Type        string // "string", "integer" or "boolean"; defaults to "string"
+	Required    bool
+	Description string
+}
+
+// Object describes a JSON object, such as a gin.H envelope, by example values:
+// Object{"message": "", "data": pkg.Interviewer{}}. A nil value means any JSON.
+type Object map[string]interface{}
+
+// File marks a multipart file field.
+type File struct{}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+	fileType      = reflect.TypeOf(File{})
+	objectType    = reflect.TypeOf(Object{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// -------------------- Serving --------------------
+
+// Handler serves the document as JSON. It is generated once, on first request.
+func (s *Spec) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s.once.Do(func() {
+			s.document, _ = json.Marshal(s.Document())
+		})
+		c.Data(http.StatusOK, "application/json; charset=utf-8", s.document)
+	}
+}
+
+// CheckRoutes reports drift between the spec and the routes a router registered:
+// routes missing from the spec and operations no route serves.
+func (s *Spec) CheckRoutes(routes gin.RoutesInfo) error {
+	documented := map[string]bool{}
+	var problems []string
+	for _, operation := range s.Operations {
+		key := operation.Method + " " + operation.Path
+		if documented[key] {
+			problems = append(problems, "documented twice: "+key)
+		}
+		documented[key] = true
+	}
+
+	registered := map[string]bool{}
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if !documented[key] {
+			problems = append(problems, "not documented: "+key)
+		}
+	}
+	for key := range documented {
+		if !registered[key] {
+			problems = append(problems, "documented but not registered: "+key)
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("openapi spec is out of date: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// -------------------- Document --------------------
+
+// Document builds the OpenAPI 3 document.
+func (s *Spec) Document() map[string]interface{} {
+	g := &generator{schemas: map[string]interface{}{}}
+	errorResponse := map[string]interface{}{
+		"description": "Problem details",
+		"content": map[string]interface{}{
+			"application/problem+json": map[string]interface{}{"schema": g.valueSchema(s.Error)},
+		},
+	}
+
+	paths := map[string]map[string]interface{}{}
+	for _, operation := range s.Operations {
+		openAPIPath, pathParameters := convertPath(operation.Path)
+		if paths[openAPIPath] == nil {
+			paths[openAPIPath] = map[string]interface{}{}
+		}
+		paths[openAPIPath][strings.ToLower(operation.Method)] = g.operation(operation, pathParameters, errorResponse)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": s.Title, "version": s.Version},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": g.schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+// convertPath turns gin path parameters (":id") into OpenAPI ones ("{id}")
+func convertPath(ginPath string) (string, []string) {
+	segments := strings.Split(ginPath, "/")
+	var parameters []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			parameters = append(parameters, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+type generator struct {
+	schemas map[string]interface{}
+}
+
+func (g *generator) operation(operation Operation, pathParameters []string, errorResponse map[string]interface{}) map[string]interface{} {
+	parameters := []interface{}{}
+	for _, name := range pathParameters {
+		parameters = append(parameters, map[string]interface{}{
+			"name": name, "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, parameter := range operation.Query {
+		typ := parameter.Type
+		if typ == "" {
+			typ = "string"
+		}
+		entry := map[string]interface{}{
+			"name": parameter.Name, "in": "query", "required": parameter.Required, "schema": map[string]interface{}{"type": typ},
+		}
+		if parameter.Description != "" {
+			entry["description"] = parameter.Description
+		}
+		parameters = append(parameters, entry)
+	}
+
+	status := operation.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	switch {
+	case len(operation.Produces) > 0:
+		content := map[string]interface{}{}
+		for _, mediaType := range operation.Produces {
+			content[mediaType] = map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}
+		}
+		success["content"] = content
+	case operation.Response != nil:
+		success["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": g.valueSchema(operation.Response)},
+		}
+	}
+
+	result := map[string]interface{}{
+		"summary":     operation.Summary,
+		"operationId": operationID(operation),
+		"parameters":  parameters,
+		"responses": map[string]interface{}{
+			fmt.Sprint(status): success,
+			"default":          errorResponse,
+		},
+	}
+	if operation.Tag != "" {
+		result["tags"] = []string{operation.Tag}
+	}
+	if operation.Deprecated {
+		result["deprecated"] = true
+	}
+	if len(operation.Roles) > 0 {
+		result["security"] = []interface{}{map[string]interface{}{"bearerAuth": []string{}}}
+		result["description"] = "Requires role: " + strings.Join(operation.Roles, " or ")
+	}
+
+	switch {
+	case operation.Request != nil:
+		result["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": g.valueSchema(operation.Request)},
+			},
+		}
+	case operation.Form != nil:
+		result["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"multipart/form-data": map[string]interface{}{"schema": g.valueSchema(operation.Form)},
+			},
+		}
+	}
+	return result
+}
+
+// operationID derives a stable ID such as "post_api_bookings_id"
+func operationID(operation Operation) string {
+	id := strings.ToLower(operation.Method) + operation.Path
+	return strings.NewReplacer("/", "_", ":", "", "*", "", "-", "_", ".", "_").Replace(id)
+}
+
+// -------------------- Schemas --------------------
+
+// valueSchema describes an example value; Objects and slices of them are expanded
+func (g *generator) valueSchema(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case nil:
+		return map[string]interface{}{}
+	case Object:
+		properties := map[string]interface{}{}
+		for name, example := range v {
+			properties[name] = g.valueSchema(example)
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	case []Object:
+		items := map[string]interface{}{"type": "object"}
+		if len(v) > 0 {
+			items = g.valueSchema(v[0])
+		}
+		return map[string]interface{}{"type": "array", "items": items}
+	}
+	return g.schema(reflect.TypeOf(value))
+}
+
+// schema describes a Go type as encoding/json marshals it. Named structs become
+// shared components, e.g. "pkg.Booking".
+func (g *generator) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case deletedAtType:
+		return map[string]interface{}{"type": "string", "format": "date-time", "nullable": true}
+	case fileType:
+		return map[string]interface{}{"type": "string", "format": "binary"}
+	case objectType:
+		return map[string]interface{}{"type": "object"}
+	}
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		return map[string]interface{}{} // custom JSON, e.g. audit snapshots
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := path.Base(t.PkgPath()) + "." + t.Name()
+		if _, ok := g.schemas[name]; !ok {
+			g.schemas[name] = map[string]interface{}{} // placeholder for recursive types
+			g.schemas[name] = g.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]interface{}{}
+}
+
+// object describes a struct's JSON fields, flattening embedded structs.
+// Fields with a "required" binding are required.
+func (g *generator) object(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	g.fields(t, properties, &required)
+
+	result := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		result["required"] = required
+	}
+	return result
+}
+
+func (g *generator) fields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			g.fields(fieldType, properties, required)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schema(field.Type)
+		for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+			if rule == "required" {
+				*required = append(*required, name)
+			}
+		}
+	}
+}
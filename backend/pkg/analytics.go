@@ -0,0 +1,473 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Analytics group dimensions
+const (
+	GroupByInterviewer = "interviewer"
+	GroupByEventType   = "event_type"
+	GroupByWeek        = "week"
+)
+
+// Analytics sources
+const (
+	AnalyticsLive    = "live"    // computed from bookings, slots and events
+	AnalyticsRollups = "rollups" // read from AnalyticsRollup rows
+)
+
+// busiestHoursShown is how many of its busiest hours each group lists
+const busiestHoursShown = 3
+
+// AnalyticsCounts are the additive measures of one interviewer, event type and
+// week, from which every rate is derived.
+type AnalyticsCounts struct {
+	OfferedMinutes   float64 `json:"offered_minutes"` // open TimeSlot and Availability time
+	BookedMinutes    float64 `json:"booked_minutes"`  // booked time of kept TimeSlot and Availability bookings
+	Bookings         int     `json:"bookings"`
+	Cancelled        int     `json:"cancelled"`
+	Completed        int     `json:"completed"` // events held
+	NoShows          int     `json:"no_shows"`
+	LeadTimeHours    float64 `json:"-"` // sum over LeadTimeBookings
+	LeadTimeBookings int     `json:"-"`
+	HourCounts       [24]int `json:"-" gorm:"serializer:json"` // kept bookings by hour of day
+}
+
+// AnalyticsRollup materializes the counts of one interviewer, event type and
+// UTC week, so reports over large datasets need not scan bookings.
+type AnalyticsRollup struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID  uint      `json:"organization_id" gorm:"uniqueIndex:idx_analytics_rollup_cell"`
+	InterviewerID   uint      `json:"interviewer_id" gorm:"uniqueIndex:idx_analytics_rollup_cell"`
+	EventTypeID     uint      `json:"event_type_id" gorm:"uniqueIndex:idx_analytics_rollup_cell"`
+	WeekStart       time.Time `json:"week_start" gorm:"uniqueIndex:idx_analytics_rollup_cell"` // Monday 00:00 UTC
+	AnalyticsCounts `gorm:"embedded"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// AnalyticsFilter selects what an analytics report covers.
+type AnalyticsFilter struct {
+	From          time.Time
+	To            time.Time // exclusive
+	InterviewerID uint      // 0 for every interviewer
+	GroupBy       []string  // any of the GroupBy constants; none gives totals only
+	Location      *time.Location
+	Source        string // AnalyticsLive or AnalyticsRollups
+}
+
+// HourCount is the number of kept bookings starting in an hour of the day.
+type HourCount struct {
+	Hour     int `json:"hour"`
+	Bookings int `json:"bookings"`
+}
+
+// AnalyticsGroup is one row of a report. Rates are nil when undefined, and
+// utilization is also nil when grouping by event type, since offered time
+// belongs to no event type.
+type AnalyticsGroup struct {
+	InterviewerID *uint      `json:"interviewer_id,omitempty"`
+	EventTypeID   *uint      `json:"event_type_id,omitempty"`
+	Week          string     `json:"week,omitempty"` // ISO week, e.g. 2025-W07
+	WeekStart     *time.Time `json:"week_start,omitempty"`
+	AnalyticsCounts
+	Utilization      *float64    `json:"utilization"`       // booked / offered minutes
+	CancellationRate *float64    `json:"cancellation_rate"` // cancelled / bookings
+	NoShowRate       *float64    `json:"no_show_rate"`      // no-shows / (completed + no-shows)
+	AvgLeadTimeHours *float64    `json:"avg_lead_time_hours"`
+	BusiestHours     []HourCount `json:"busiest_hours"`
+}
+
+// AnalyticsReport is the result of SchedulingAnalytics.
+type AnalyticsReport struct {
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	TimeZone string           `json:"time_zone"`
+	GroupBy  []string         `json:"group_by"`
+	Source   string           `json:"source"`
+	Totals   AnalyticsGroup   `json:"totals"`
+	Groups   []AnalyticsGroup `json:"groups"`
+}
+
+// analyticsCell keys the counts of one interviewer, event type and week
+type analyticsCell struct {
+	InterviewerID uint
+	EventTypeID   uint
+	Week          int64 // Unix time of the week's start
+}
+
+// availabilityWindow reads the availabilities table, whose model lives in the handler package
+type availabilityWindow struct {
+	ID             uint
+	OrganizationID uint
+	InterviewerID  uint
+	StartTime      time.Time
+	EndTime        time.Time
+	DeletedAt      gorm.DeletedAt
+}
+
+func (availabilityWindow) TableName() string {
+	return "availabilities"
+}
+
+// -------------------- Reports --------------------
+
+// SchedulingAnalytics reports utilization, lead time, cancellation and no-show
+// rates and busiest hours for the filter's period. Rollups cover whole UTC weeks,
+// so with AnalyticsRollups the period is widened to week boundaries.
+func SchedulingAnalytics(db *gorm.DB, filter AnalyticsFilter) (*AnalyticsReport, error) {
+	if filter.Location == nil {
+		filter.Location = time.UTC
+	}
+	for _, dimension := range filter.GroupBy {
+		if dimension != GroupByInterviewer && dimension != GroupByEventType && dimension != GroupByWeek {
+			return nil, Validation("invalid_group_by", "group_by must be interviewer, event_type or week")
+		}
+	}
+	if !filter.From.Before(filter.To) {
+		return nil, Validation("invalid_time_range", "from must be before to")
+	}
+
+	var cells map[analyticsCell]*AnalyticsCounts
+	var err error
+	switch filter.Source {
+	case "", AnalyticsLive:
+		filter.Source = AnalyticsLive
+		cells, err = computeAnalytics(db, filter.From, filter.To, filter.InterviewerID, filter.Location)
+	case AnalyticsRollups:
+		if filter.Location != time.UTC {
+			return nil, Validation("rollups_are_utc", "rollups are kept per UTC week; omit the time zone")
+		}
+		filter.From, filter.To = weekStart(filter.From, time.UTC), weekEnd(filter.To, time.UTC)
+		cells, err = readAnalyticsRollups(db, filter.From, filter.To, filter.InterviewerID)
+	default:
+		return nil, Validation("invalid_source", "source must be live or rollups")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	groups, totals := groupAnalytics(cells, filter.GroupBy, filter.Location)
+	return &AnalyticsReport{
+		From:     filter.From,
+		To:       filter.To,
+		TimeZone: filter.Location.String(),
+		GroupBy:  filter.GroupBy,
+		Source:   filter.Source,
+		Totals:   totals,
+		Groups:   groups,
+	}, nil
+}
+
+// RefreshAnalyticsRollups recomputes the rollups of every UTC week touching
+// [from, to) for the session's organization and returns how many were stored.
+func RefreshAnalyticsRollups(db *gorm.DB, from, to time.Time) (int, error) {
+	from, to = weekStart(from, time.UTC), weekEnd(to, time.UTC)
+	if !from.Before(to) {
+		return 0, Validation("invalid_time_range", "from must be before to")
+	}
+
+	cells, err := computeAnalytics(db, from, to, 0, time.UTC)
+	if err != nil {
+		return 0, err
+	}
+	rollups := make([]AnalyticsRollup, 0, len(cells))
+	for cell, counts := range cells {
+		rollups = append(rollups, AnalyticsRollup{
+			InterviewerID:   cell.InterviewerID,
+			EventTypeID:     cell.EventTypeID,
+			WeekStart:       time.Unix(cell.Week, 0).UTC(),
+			AnalyticsCounts: *counts,
+			UpdatedAt:       time.Now(),
+		})
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("week_start >= ? AND week_start < ?", from, to).Delete(&AnalyticsRollup{}).Error; err != nil {
+			return errors.New("failed to clear analytics rollups")
+		}
+		if len(rollups) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(&rollups, exportBatchSize).Error; err != nil {
+			return errors.New("failed to store analytics rollups")
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(rollups), nil
+}
+
+// -------------------- Computation --------------------
+
+// computeAnalytics counts offered time, bookings and held events per cell,
+// reading each table in batches.
+func computeAnalytics(db *gorm.DB, from, to time.Time, interviewerID uint, loc *time.Location) (map[analyticsCell]*AnalyticsCounts, error) {
+	cells := map[analyticsCell]*AnalyticsCounts{}
+	cellFor := func(interviewerID, eventTypeID uint, at time.Time) *AnalyticsCounts {
+		key := analyticsCell{InterviewerID: interviewerID, EventTypeID: eventTypeID, Week: weekStart(at, loc).Unix()}
+		if cells[key] == nil {
+			cells[key] = &AnalyticsCounts{}
+		}
+		return cells[key]
+	}
+	scope := func(query *gorm.DB) *gorm.DB {
+		if interviewerID != 0 {
+			query = query.Where("interviewer_id = ?", interviewerID)
+		}
+		return query
+	}
+	// offer spreads an open interval over the weeks it spans, clipped to the period
+	offer := func(interviewerID uint, start, end time.Time) {
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		for start.Before(end) {
+			next := weekStart(start, loc).AddDate(0, 0, 7)
+			if next.After(end) {
+				next = end
+			}
+			cellFor(interviewerID, 0, start).OfferedMinutes += next.Sub(start).Minutes()
+			start = next
+		}
+	}
+
+	var slots []TimeSlot
+	result := scope(db.Where("start_time < ? AND end_time > ?", to, from)).FindInBatches(&slots, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, slot := range slots {
+			offer(slot.InterviewerID, slot.StartTime, slot.EndTime)
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return nil, errors.New("failed to read time slots for analytics")
+	}
+
+	var windows []availabilityWindow
+	result = scope(db.Where("start_time < ? AND end_time > ?", to, from)).FindInBatches(&windows, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, window := range windows {
+			offer(window.InterviewerID, window.StartTime, window.EndTime)
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return nil, errors.New("failed to read availability for analytics")
+	}
+
+	// Schedule bookings use weekly-template time, which is not part of offered minutes
+	eventTypes := map[uint]uint{} // booking ID to event type, for the events below
+	var bookings []Booking
+	result = scope(db.Where("start_time >= ? AND start_time < ?", from, to)).FindInBatches(&bookings, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, booking := range bookings {
+			eventTypes[booking.ID] = booking.EventTypeID
+			counts := cellFor(booking.InterviewerID, booking.EventTypeID, booking.StartTime)
+			counts.Bookings++
+			if booking.CreatedAt.Before(booking.StartTime) {
+				counts.LeadTimeHours += booking.StartTime.Sub(booking.CreatedAt).Hours()
+				counts.LeadTimeBookings++
+			}
+			if booking.Status == "cancelled" {
+				counts.Cancelled++
+				continue
+			}
+			counts.HourCounts[booking.StartTime.In(loc).Hour()]++
+			if booking.SlotKind != SlotKindSchedule {
+				counts.BookedMinutes += booking.EndTime.Sub(booking.StartTime).Minutes()
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return nil, errors.New("failed to read bookings for analytics")
+	}
+
+	var events []Event
+	query := db.Where("status IN ? AND start_time >= ? AND start_time < ?", []string{EventCompleted, EventNoShow}, from, to)
+	result = scope(query).FindInBatches(&events, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, event := range events {
+			counts := cellFor(event.InterviewerID, eventTypes[event.BookingID], event.StartTime)
+			if event.Status == EventNoShow {
+				counts.NoShows++
+			} else {
+				counts.Completed++
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return nil, errors.New("failed to read events for analytics")
+	}
+
+	return cells, nil
+}
+
+// readAnalyticsRollups loads the stored cells of [from, to), which must be whole UTC weeks
+func readAnalyticsRollups(db *gorm.DB, from, to time.Time, interviewerID uint) (map[analyticsCell]*AnalyticsCounts, error) {
+	query := db.Where("week_start >= ? AND week_start < ?", from, to)
+	if interviewerID != 0 {
+		query = query.Where("interviewer_id = ?", interviewerID)
+	}
+
+	cells := map[analyticsCell]*AnalyticsCounts{}
+	var rollups []AnalyticsRollup
+	result := query.FindInBatches(&rollups, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, rollup := range rollups {
+			counts := rollup.AnalyticsCounts
+			cells[analyticsCell{InterviewerID: rollup.InterviewerID, EventTypeID: rollup.EventTypeID, Week: rollup.WeekStart.Unix()}] = &counts
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return nil, errors.New("failed to read analytics rollups")
+	}
+	return cells, nil
+}
+
+// -------------------- Grouping --------------------
+
+// groupAnalytics sums cells into the requested groups and the overall totals
+func groupAnalytics(cells map[analyticsCell]*AnalyticsCounts, groupBy []string, loc *time.Location) ([]AnalyticsGroup, AnalyticsGroup) {
+	byInterviewer, byEventType, byWeek := false, false, false
+	for _, dimension := range groupBy {
+		switch dimension {
+		case GroupByInterviewer:
+			byInterviewer = true
+		case GroupByEventType:
+			byEventType = true
+		case GroupByWeek:
+			byWeek = true
+		}
+	}
+
+	var total AnalyticsCounts
+	grouped := map[analyticsCell]*AnalyticsCounts{}
+	for cell, counts := range cells {
+		total.add(counts)
+
+		key := analyticsCell{}
+		if byInterviewer {
+			key.InterviewerID = cell.InterviewerID
+		}
+		if byEventType {
+			key.EventTypeID = cell.EventTypeID
+		}
+		if byWeek {
+			key.Week = cell.Week
+		}
+		if grouped[key] == nil {
+			grouped[key] = &AnalyticsCounts{}
+		}
+		grouped[key].add(counts)
+	}
+
+	groups := []AnalyticsGroup{}
+	if len(groupBy) > 0 {
+		for key, counts := range grouped {
+			group := newAnalyticsGroup(*counts, !byEventType)
+			if byInterviewer {
+				interviewerID := key.InterviewerID
+				group.InterviewerID = &interviewerID
+			}
+			if byEventType {
+				eventTypeID := key.EventTypeID
+				group.EventTypeID = &eventTypeID
+			}
+			if byWeek {
+				start := time.Unix(key.Week, 0).In(loc)
+				year, week := start.ISOWeek()
+				group.Week = fmt.Sprintf("%d-W%02d", year, week)
+				group.WeekStart = &start
+			}
+			groups = append(groups, group)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if a.InterviewerID != nil && *a.InterviewerID != *b.InterviewerID {
+			return *a.InterviewerID < *b.InterviewerID
+		}
+		if a.EventTypeID != nil && *a.EventTypeID != *b.EventTypeID {
+			return *a.EventTypeID < *b.EventTypeID
+		}
+		return a.WeekStart != nil && a.WeekStart.Before(*b.WeekStart)
+	})
+
+	return groups, newAnalyticsGroup(total, true)
+}
+
+// newAnalyticsGroup derives the rates of summed counts
+func newAnalyticsGroup(counts AnalyticsCounts, withUtilization bool) AnalyticsGroup {
+	ratio := func(part, whole float64) *float64 {
+		if whole == 0 {
+			return nil
+		}
+		value := part / whole
+		return &value
+	}
+
+	group := AnalyticsGroup{
+		AnalyticsCounts:  counts,
+		CancellationRate: ratio(float64(counts.Cancelled), float64(counts.Bookings)),
+		NoShowRate:       ratio(float64(counts.NoShows), float64(counts.Completed+counts.NoShows)),
+		AvgLeadTimeHours: ratio(counts.LeadTimeHours, float64(counts.LeadTimeBookings)),
+		BusiestHours:     []HourCount{},
+	}
+	if withUtilization {
+		group.Utilization = ratio(counts.BookedMinutes, counts.OfferedMinutes)
+	}
+
+	for hour, bookings := range counts.HourCounts {
+		if bookings > 0 {
+			group.BusiestHours = append(group.BusiestHours, HourCount{Hour: hour, Bookings: bookings})
+		}
+	}
+	sort.SliceStable(group.BusiestHours, func(i, j int) bool {
+		return group.BusiestHours[i].Bookings > group.BusiestHours[j].Bookings
+	})
+	if len(group.BusiestHours) > busiestHoursShown {
+		group.BusiestHours = group.BusiestHours[:busiestHoursShown]
+	}
+	return group
+}
+
+// add sums other into c
+func (c *AnalyticsCounts) add(other *AnalyticsCounts) {
+	c.OfferedMinutes += other.OfferedMinutes
+	c.BookedMinutes += other.BookedMinutes
+	c.Bookings += other.Bookings
+	c.Cancelled += other.Cancelled
+	c.Completed += other.Completed
+	c.NoShows += other.NoShows
+	c.LeadTimeHours += other.LeadTimeHours
+	c.LeadTimeBookings += other.LeadTimeBookings
+	for hour := range c.HourCounts {
+		c.HourCounts[hour] += other.HourCounts[hour]
+	}
+}
+
+// weekStart returns the Monday 00:00 in loc of the week containing t
+func weekStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, loc)
+}
+
+// weekEnd returns the start of the week after the one containing t, or t itself
+// when it already starts a week
+func weekEnd(t time.Time, loc *time.Location) time.Time {
+	start := weekStart(t, loc)
+	if start.Equal(t) {
+		return start
+	}
+	return start.AddDate(0, 0, 7)
+}
@@ -0,0 +1,382 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Audit actions
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditedTables lists the scheduling tables whose every create, update and delete is logged.
+var AuditedTables = map[string]bool{
+	"time_slots":             true,
+	"availabilities":         true,
+	"availability_overrides": true,
+	"schedule_templates":     true,
+	"bookings":               true,
+	"events":                 true,
+}
+
+// auditRedactedColumns never appear in audit snapshots.
+var auditRedactedColumns = map[string]bool{"manage_token": true, "verify_token": true}
+
+// -------------------- Models --------------------
+
+// AuditJSON is a JSON snapshot stored as text and returned as embedded JSON.
+type AuditJSON string
+
+// MarshalJSON embeds the snapshot as JSON, or null when there is none.
+func (j AuditJSON) MarshalJSON() ([]byte, error) {
+	if j == "" {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
+
+// AuditLog is an append-only record of one change to a scheduling row.
+type AuditLog struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"index"`
+	Actor          string    `json:"actor" gorm:"index"` // token subject, "candidate:<email>", "anonymous" or "system"
+	Action         string    `json:"action"`
+	EntityType     string    `json:"entity_type" gorm:"index:idx_audit_entity"` // table name, e.g. "bookings"
+	EntityID       string    `json:"entity_id" gorm:"index:idx_audit_entity"`
+	Before         AuditJSON `json:"before" gorm:"type:text"`
+	After          AuditJSON `json:"after" gorm:"type:text"`
+	RequestID      string    `json:"request_id,omitempty" gorm:"index"`
+	CreatedAt      time.Time `json:"created_at" gorm:"index"`
+}
+
+// AuditLogFilter narrows ListAuditLogs. Zero values match everything.
+type AuditLogFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	RequestID  string
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Offset     int
+}
+
+// -------------------- Audit Context --------------------
+
+// auditKey stores who is making changes in a context.
+type auditKey struct{}
+
+type auditContext struct {
+	actor     string
+	requestID string
+}
+
+// WithAuditContext returns a context whose database changes are logged against actor and requestID.
+func WithAuditContext(ctx context.Context, actor, requestID string) context.Context {
+	return context.WithValue(ctx, auditKey{}, auditContext{actor: actor, requestID: requestID})
+}
+
+// WithActor returns a session whose changes are logged against actor, keeping the request ID.
+func WithActor(db *gorm.DB, actor string) *gorm.DB {
+	current, _ := db.Statement.Context.Value(auditKey{}).(auditContext)
+	return db.WithContext(WithAuditContext(db.Statement.Context, actor, current.requestID))
+}
+
+// auditContextOf returns the actor and request ID of a context; changes made
+// outside a request, such as background jobs, belong to "system".
+func auditContextOf(ctx context.Context) auditContext {
+	if ctx != nil {
+		if current, ok := ctx.Value(auditKey{}).(auditContext); ok && current.actor != "" {
+			return current
+		}
+	}
+	return auditContext{actor: "system"}
+}
+
+// -------------------- Audit Functions --------------------
+
+// RecordAudit appends an audit entry in the session's transaction and organization.
+func RecordAudit(db *gorm.DB, action, entityType, entityID string, before, after map[string]interface{}) error {
+	current := auditContextOf(db.Statement.Context)
+	entry := AuditLog{
+		Actor:      current.actor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     auditSnapshot(before),
+		After:      auditSnapshot(after),
+		RequestID:  current.requestID,
+		CreatedAt:  time.Now(),
+	}
+	if err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Create(&entry).Error; err != nil {
+		return errors.New("failed to write audit log")
+	}
+	return nil
+}
+
+// ListAuditLogs returns matching entries newest first, with the total number of matches.
+func ListAuditLogs(db *gorm.DB, filter AuditLogFilter) ([]AuditLog, int64, error) {
+	query := db.Model(&AuditLog{})
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To.UTC())
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.New("failed to count audit logs")
+	}
+
+	logs := []AuditLog{}
+	if err := query.Order("id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&logs).Error; err != nil {
+		return nil, 0, errors.New("failed to fetch audit logs")
+	}
+	return logs, total, nil
+}
+
+// ProtectAuditLog makes PostgreSQL reject updates, deletes and truncation of
+// audit_logs, so raw SQL cannot rewrite history either. Other drivers rely on
+// the callbacks of RegisterAuditLog, which only see changes made through gorm.
+func ProtectAuditLog(db *gorm.DB) error {
+	if db.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit log is append-only';
+END
+$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs`,
+		`CREATE TRIGGER audit_logs_append_only BEFORE UPDATE OR DELETE ON audit_logs FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only()`,
+		`DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs`,
+		`CREATE TRIGGER audit_logs_no_truncate BEFORE TRUNCATE ON audit_logs FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only()`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to protect audit log: %w", err)
+		}
+	}
+	return nil
+}
+
+// -------------------- Audit Callbacks --------------------
+
+// RegisterAuditLog adds callbacks that log every create, update and delete on
+// AuditedTables, and reject any change to existing audit entries. Register it
+// after RegisterTenantScope so snapshots only read the session's organization.
+// Raw SQL bypasses the callbacks; on PostgreSQL, ProtectAuditLog still keeps
+// entries from changing.
+func RegisterAuditLog(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("audit:create", auditCreated); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").After("tenant:update").Register("audit:before_update", auditSnapshotBefore); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("audit:update", auditChanged(AuditUpdate)); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").After("tenant:delete").Register("audit:before_delete", auditSnapshotBefore); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:delete").Register("audit:delete", auditChanged(AuditDelete))
+}
+
+// auditBeforeKey stores the rows an update or delete is about to change.
+const auditBeforeKey = "audit:before"
+
+func auditCreated(db *gorm.DB) {
+	table := db.Statement.Table
+	if db.Error != nil || !AuditedTables[table] || db.Statement.Schema == nil || db.RowsAffected == 0 {
+		return
+	}
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return
+	}
+
+	ids := primaryKeys(db, field.Name)
+	rows, err := auditRows(db, table, field.DBName, ids)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	for _, id := range ids {
+		key := fmt.Sprint(id)
+		db.AddError(RecordAudit(db, AuditCreate, table, key, nil, rows[key]))
+	}
+}
+
+func auditSnapshotBefore(db *gorm.DB) {
+	table := db.Statement.Table
+	if table == "audit_logs" {
+		db.AddError(errors.New("audit log is append-only"))
+		return
+	}
+	if db.Error != nil || !AuditedTables[table] || db.Statement.SQL.Len() > 0 {
+		return
+	}
+
+	primaryKey := "id"
+	query := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Table(table)
+	if db.Statement.Model != nil {
+		query = query.Model(db.Statement.Model) // resolves primary key conditions such as Delete(&Event{}, id)
+	}
+	conditions := 0
+	if where, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+		query.Statement.AddClause(where)
+		conditions++
+	}
+	if schema := db.Statement.Schema; schema != nil && schema.PrioritizedPrimaryField != nil {
+		field := schema.PrioritizedPrimaryField
+		primaryKey = field.DBName
+		if ids := primaryKeys(db, field.Name); len(ids) > 0 {
+			query = query.Where(clause.IN{Column: clause.Column{Table: table, Name: primaryKey}, Values: ids})
+			conditions++
+		}
+	}
+	if conditions == 0 {
+		return // gorm refuses global updates and deletes anyway
+	}
+
+	var rows []map[string]interface{}
+	if err := query.Find(&rows).Error; err != nil {
+		db.AddError(errors.New("failed to read audit snapshot"))
+		return
+	}
+	if len(rows) > 0 {
+		db.InstanceSet(auditBeforeKey, auditSnapshotRows{primaryKey: primaryKey, rows: rows})
+	}
+}
+
+// auditSnapshotRows are the rows read before an update or delete.
+type auditSnapshotRows struct {
+	primaryKey string
+	rows       []map[string]interface{}
+}
+
+func auditChanged(action string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(auditBeforeKey)
+		if !ok || db.Error != nil || db.RowsAffected == 0 {
+			return
+		}
+		before := value.(auditSnapshotRows)
+		table := db.Statement.Table
+
+		ids := make([]interface{}, 0, len(before.rows))
+		for _, row := range before.rows {
+			ids = append(ids, row[before.primaryKey])
+		}
+		after, err := auditRows(db, table, before.primaryKey, ids)
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+
+		for _, row := range before.rows {
+			key := fmt.Sprint(row[before.primaryKey])
+			if action == AuditUpdate && auditSnapshot(row) == auditSnapshot(after[key]) {
+				continue // rows the statement matched but did not change
+			}
+			db.AddError(RecordAudit(db, action, table, key, row, after[key]))
+		}
+	}
+}
+
+// auditRows loads rows by primary key, keyed by the key's string form.
+func auditRows(db *gorm.DB, table, primaryKey string, ids []interface{}) (map[string]map[string]interface{}, error) {
+	byID := map[string]map[string]interface{}{}
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	var rows []map[string]interface{}
+	err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Table(table).
+		Where(clause.IN{Column: clause.Column{Name: primaryKey}, Values: ids}).Find(&rows).Error
+	if err != nil {
+		return nil, errors.New("failed to read audit snapshot")
+	}
+	for _, row := range rows {
+		byID[fmt.Sprint(row[primaryKey])] = row
+	}
+	return byID, nil
+}
+
+// primaryKeys returns the non-zero primary keys of the statement's model value(s).
+func primaryKeys(db *gorm.DB, fieldName string) []interface{} {
+	field := db.Statement.Schema.LookUpField(fieldName)
+	ctx := db.Statement.Context
+	ids := []interface{}{}
+
+	collect := func(value interface{}, zero bool) {
+		if !zero {
+			ids = append(ids, value)
+		}
+	}
+	value := db.Statement.ReflectValue
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if item := reflect.Indirect(value.Index(i)); item.Kind() == reflect.Struct {
+				collect(field.ValueOf(ctx, item))
+			}
+		}
+	case reflect.Struct:
+		collect(field.ValueOf(ctx, value))
+	}
+	return ids
+}
+
+// auditSnapshot encodes a row as JSON without redacted columns.
+func auditSnapshot(row map[string]interface{}) AuditJSON {
+	if row == nil {
+		return ""
+	}
+	clean := make(map[string]interface{}, len(row))
+	for column, value := range row {
+		if auditRedactedColumns[column] {
+			continue
+		}
+		if raw, ok := value.([]byte); ok {
+			value = string(raw)
+		}
+		clean[column] = value
+	}
+	encoded, err := json.Marshal(clean)
+	if err != nil {
+		return ""
+	}
+	return AuditJSON(encoded)
+}
@@ -1,7 +1,11 @@
 package pkg
 
 import (
+	"BookingTimeSlot/backend/tracing"
 	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,171 +15,538 @@ import (
 
 // Booking holds information about a booking.
 type Booking struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	Name        string    `json:"name"`
-	Email       string    `json:"email"`
-	SlotID      uint      `json:"slot_id"`
-	UserID      int       `json:"user_id"`
-	BookingDate time.Time `json:"booking_date"`
-	Status      string    `json:"status"`
-	Slot        string    `json:"slot"`
-	Date        string    `json:"date"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID             uint            `json:"id" gorm:"primaryKey"`
+	OrganizationID uint            `json:"organization_id" gorm:"index"`
+	Name           string          `json:"name"`
+	Email          string          `json:"email"`
+	SlotID         uint            `json:"slot_id"`
+	SlotKind       string          `json:"slot_kind,omitempty"` // which model SlotID refers to, see SlotKind constants
+	UserID         int             `json:"user_id"`
+	InterviewerID  uint            `json:"interviewer_id" gorm:"index"`
+	EventTypeID    uint            `json:"event_type_id,omitempty"`
+	BookingDate    time.Time       `json:"booking_date"`
+	Status         string          `json:"status"`
+	StartTime      time.Time       `json:"start_time" gorm:"index"`
+	EndTime        time.Time       `json:"end_time"`
+	Answers        []BookingAnswer `json:"answers,omitempty" gorm:"foreignKey:BookingID"`
+	ManageToken    *string         `json:"-" gorm:"uniqueIndex"` // SHA-256 of the candidate's manage link token
+	ManageExpires  *time.Time      `json:"-"`
+	VerifyToken    *string         `json:"-" gorm:"uniqueIndex"`                  // SHA-256 of the candidate's email verification token
+	VerifyExpires  *time.Time      `json:"verify_expires,omitempty" gorm:"index"` // when an unverified booking releases its slot
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
 }
 
 // TimeSlot represents available interview time slots.
 type TimeSlot struct {
-	ID            uint      `gorm:"primaryKey" json:"id"`
-	InterviewerID uint      `json:"interviewer_id" gorm:"index"`
-	StartTime     time.Time `json:"start_time"`
-	EndTime       time.Time `json:"end_time"`
-	IsBooked      bool      `json:"is_booked"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	OrganizationID uint      `json:"organization_id" gorm:"index"`
+	InterviewerID  uint      `json:"interviewer_id" gorm:"index"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	IsBooked       bool      `json:"is_booked"`
+	Version        uint      `json:"version" gorm:"not null;default:1"` // counts changes; see RegisterVersioning
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 // -------------------- Auto Migration --------------------
 
-// AutoMigrateTables initializes all tables.
+// MigratedModels are the pkg models AutoMigrateTables creates tables for.
+var MigratedModels = []interface{}{
+	&Organization{}, &TimeSlot{}, &Booking{}, &Event{}, &AvailabilityOverride{}, &Holiday{}, &ScheduleTemplate{}, &EventType{},
+	&WaitlistEntry{}, &WaitlistOffer{}, &Interviewer{}, &IntakeQuestion{}, &BookingAnswer{}, &AuditLog{}, &AnalyticsRollup{},
+	&IdempotencyKey{},
+}
+
+// AutoMigrateTables initializes all tables, makes the audit log append-only on
+// PostgreSQL and assigns rows without an organization to the default one.
 func AutoMigrateTables(db *gorm.DB) error {
-	return db.AutoMigrate(&TimeSlot{}, &Booking{})
+	if err := migrateTenantIndexes(db); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(MigratedModels...); err != nil {
+		return err
+	}
+	if err := migrateSlotKinds(db); err != nil {
+		return err
+	}
+	if err := migrateBookingTimes(db); err != nil {
+		return err
+	}
+	if err := migrateOfferTokens(db); err != nil {
+		return err
+	}
+	if err := ProtectAuditLog(db); err != nil {
+		return err
+	}
+	return AssignDefaultOrganization(db, tenantTables...)
+}
+
+// migrateSlotKinds records the slot kind of bookings made before it was kept,
+// so every booking says which model its SlotID refers to. Bookings without a
+// slot were of the weekly schedule; of the others, those that kept their slot
+// as text booked a time slot and the rest an availability window.
+func migrateSlotKinds(db *gorm.DB) error {
+	kind := "CASE WHEN slot_id = 0 THEN ? ELSE ? END"
+	args := []interface{}{SlotKindSchedule, SlotKindAvailability}
+	if db.Migrator().HasColumn(&Booking{}, "slot") {
+		kind = "CASE WHEN slot_id = 0 THEN ? WHEN slot <> '' THEN ? ELSE ? END"
+		args = []interface{}{SlotKindSchedule, SlotKindTimeSlot, SlotKindAvailability}
+	}
+	err := db.Table("bookings").Where("slot_kind IS NULL OR slot_kind = ''").
+		Update("slot_kind", gorm.Expr(kind, args...)).Error
+	if err != nil {
+		return fmt.Errorf("failed to record slot kinds of bookings: %w", err)
+	}
+	return nil
+}
+
+// migrateBookingTimes fills start_time and end_time of bookings made before
+// they existed, when a booking kept its slot as "15:04 - 15:04" text next to
+// a YYYY-MM-DD date, then drops those columns. Bookings without the text take
+// the times of the slot they booked; while one has neither, the columns stay.
+func migrateBookingTimes(db *gorm.DB) error {
+	migrator := db.Migrator()
+	hasSlot, hasDate := migrator.HasColumn(&Booking{}, "slot"), migrator.HasColumn(&Booking{}, "date")
+	if !hasSlot && !hasDate {
+		return nil
+	}
+
+	columns := "id, slot_id, slot_kind, '' AS slot, '' AS date"
+	switch {
+	case hasSlot && hasDate:
+		columns = "id, slot_id, slot_kind, slot, date"
+	case hasDate:
+		columns = "id, slot_id, slot_kind, '' AS slot, date"
+	}
+	var legacy []struct {
+		ID       uint
+		SlotID   uint
+		SlotKind string
+		Slot     string
+		Date     string
+	}
+	if err := db.Table("bookings").Select(columns).Where("start_time IS NULL").Scan(&legacy).Error; err != nil {
+		return fmt.Errorf("failed to fetch bookings without times: %w", err)
+	}
+
+	unfilled := 0
+	for _, booking := range legacy {
+		var times struct{ StartTime, EndTime time.Time }
+		if from, to, ok := strings.Cut(booking.Slot, " - "); ok {
+			start, errStart := time.Parse("2006-01-02 15:04", booking.Date+" "+from)
+			end, errEnd := time.Parse("2006-01-02 15:04", booking.Date+" "+to)
+			if errStart == nil && errEnd == nil {
+				times.StartTime, times.EndTime = start, end
+			}
+		}
+		if times.StartTime.IsZero() && booking.SlotID != 0 {
+			table := "availabilities"
+			if booking.SlotKind == SlotKindTimeSlot {
+				table = "time_slots"
+			}
+			if migrator.HasTable(table) {
+				err := db.Table(table).Select("start_time, end_time").Where("id = ?", booking.SlotID).Limit(1).Scan(&times).Error
+				if err != nil {
+					return fmt.Errorf("failed to fetch the slot of booking %d: %w", booking.ID, err)
+				}
+			}
+		}
+		if times.StartTime.IsZero() {
+			slog.Warn("Booking has no slot to take its times from; keeping its legacy columns", "booking_id", booking.ID)
+			unfilled++
+			continue
+		}
+		err := db.Table("bookings").Where("id = ?", booking.ID).
+			Updates(map[string]interface{}{"start_time": times.StartTime, "end_time": times.EndTime}).Error
+		if err != nil {
+			return fmt.Errorf("failed to fill times of booking %d: %w", booking.ID, err)
+		}
+	}
+
+	if unfilled > 0 {
+		return nil
+	}
+	for _, column := range []string{"slot", "date"} {
+		if migrator.HasColumn(&Booking{}, column) {
+			if err := migrator.DropColumn(&Booking{}, column); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CheckMigrations reports the first model whose table or columns are missing,
+// e.g. when a new build runs against a database it has not migrated yet.
+func CheckMigrations(db *gorm.DB, models ...interface{}) error {
+	for _, model := range models {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			return err
+		}
+		table := statement.Schema.Table
+
+		columnTypes, err := db.Migrator().ColumnTypes(model)
+		if err != nil || len(columnTypes) == 0 {
+			return fmt.Errorf("table %s is missing", table)
+		}
+		columns := map[string]bool{}
+		for _, columnType := range columnTypes {
+			columns[columnType.Name()] = true
+		}
+		for _, field := range statement.Schema.Fields {
+			if field.DBName != "" && !columns[field.DBName] {
+				return fmt.Errorf("column %s.%s is missing", table, field.DBName)
+			}
+		}
+	}
+	return nil
 }
 
 // -------------------- Booking Functions --------------------
 
-// CreateBooking inserts a new booking and marks the timeslot as booked.
-func CreateBooking(db *gorm.DB, userID int, slotID uint, name, email, status string) (*Booking, error) {
+// MaxActiveBookingsPerEmail caps how many upcoming bookings one candidate email
+// may hold, so a script cannot book out an interviewer's week. 0 means no cap.
+var MaxActiveBookingsPerEmail = 5
+
+// CheckActiveBookingLimit rejects a new booking for email once it already holds
+// MaxActiveBookingsPerEmail upcoming bookings that are not cancelled.
+func CheckActiveBookingLimit(db *gorm.DB, email string) error {
+	if MaxActiveBookingsPerEmail <= 0 {
+		return nil
+	}
+	var active int64
+	err := db.Model(&Booking{}).
+		Where("LOWER(email) = ? AND start_time > ? AND status <> ?", strings.ToLower(strings.TrimSpace(email)), time.Now().UTC(), "cancelled").
+		Count(&active).Error
+	if err != nil {
+		return err
+	}
+	if active >= int64(MaxActiveBookingsPerEmail) {
+		return Conflict("active_booking_limit", fmt.Sprintf("at most %d upcoming bookings are allowed per email address", MaxActiveBookingsPerEmail))
+	}
+	return nil
+}
+
+// PlaceBooking saves a new candidate booking with its calendar event; every
+// booking route goes through it. The booking is booked, or with
+// VerifyBookingEmails set pending_verification until the candidate follows the
+// emailed link. The interviewer is locked for the transaction in which reserve
+// claims the slot, e.g. marks it booked, and the active booking limit is
+// checked. Once committed, the candidate gets the verification link, or the
+// interviewer hears of the booking and the candidate gets the manage link.
+func PlaceBooking(db *gorm.DB, booking *Booking, reserve func(tx *gorm.DB) error) error {
+	booking.Status = "booked"
+	if VerifyBookingEmails {
+		booking.Status = BookingPendingVerification
+	}
+	booking.BookingDate = time.Now()
+
+	txCtx, span := tracing.Tracer().Start(db.Statement.Context, "booking transaction")
+	err := db.WithContext(txCtx).Transaction(func(tx *gorm.DB) error {
+		if err := lockInterviewer(tx, booking.InterviewerID); err != nil {
+			return err
+		}
+		if reserve != nil {
+			if err := reserve(tx); err != nil {
+				return err
+			}
+		}
+		if err := CheckActiveBookingLimit(tx, booking.Email); err != nil {
+			return err
+		}
+		if err := tx.Create(booking).Error; err != nil {
+			return errors.New("failed to create booking")
+		}
+		return CreateBookingEvent(tx, booking)
+	})
+	tracing.End(span, err)
+	if err != nil {
+		return err
+	}
+
+	// An unverified booking is announced once its candidate confirms their email
+	if booking.Status == BookingPendingVerification {
+		SendVerificationLink(db, booking)
+	} else {
+		NotifyInterviewerOfBooking(db, booking.InterviewerID, booking.Name, booking.Email, booking.StartTime, booking.Answers)
+		SendManageLink(db, booking.ID, booking.Name, booking.Email, booking.StartTime, booking.EndTime)
+	}
+	return nil
+}
+
+// CreateBooking books the stored time slot of booking.SlotID for the candidate
+// of booking, taking the interviewer and times from the slot.
+func CreateBooking(db *gorm.DB, booking *Booking) error {
 	// Validate input fields
-	if name == "" || email == "" {
-		return nil, errors.New("name and email are required")
+	booking.Email = strings.TrimSpace(booking.Email)
+	if booking.Name == "" || booking.Email == "" {
+		return Validation("name_and_email_required", "name and email are required")
+	}
+	if err := ValidateEmail(booking.Email); err != nil {
+		return err
 	}
 
 	// Check if the timeslot exists
 	var slot TimeSlot
-	if err := db.First(&slot, slotID).Error; err != nil {
-		return nil, errors.New("time slot not found")
+	if err := db.First(&slot, booking.SlotID).Error; err != nil {
+		return NotFound("slot_not_found", "time slot not found")
 	}
 
 	// Validate slot availability
 	if slot.IsBooked {
-		return nil, errors.New("time slot is already booked")
+		return Conflict("slot_already_booked", "time slot is already booked")
 	}
 
 	// Validate booking date (no past dates)
 	if slot.StartTime.Before(time.Now()) {
-		return nil, errors.New("cannot book a past time slot")
+		return PastSlot("slot_in_past", "cannot book a past time slot")
 	}
 
-	booking := Booking{
-		Name:        name,
-		Email:       email,
-		SlotID:      slotID,
-		UserID:      userID,
-		BookingDate: time.Now(),
-		Status:      status,
-		Slot:        slot.StartTime.Format("15:04") + " - " + slot.EndTime.Format("15:04"),
-		Date:        slot.StartTime.Format("2006-01-02"),
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	// Slots freed by a cancellation are reserved for the waitlist first
+	held, err := HeldSlotIDs(db, SlotKindTimeSlot)
+	if err != nil {
+		return err
+	}
+	if held[slot.ID] {
+		return Conflict("slot_held", "time slot is held for a waitlisted candidate")
 	}
 
-	// Transaction: Create booking and update timeslot
-	err := db.Transaction(func(tx *gorm.DB) error {
-		// Create booking record
-		if err := tx.Create(&booking).Error; err != nil {
-			return errors.New("failed to create booking")
-		}
-
-		// Mark the slot as booked
-		if err := tx.Model(&slot).Update("is_booked", true).Error; err != nil {
-			return errors.New("failed to update slot status")
-		}
-
-		return nil
+	booking.SlotKind = SlotKindTimeSlot
+	booking.InterviewerID = slot.InterviewerID
+	booking.StartTime = slot.StartTime
+	booking.EndTime = slot.EndTime
+	return PlaceBooking(db, booking, func(tx *gorm.DB) error {
+		return reserveTimeSlot(tx, slot.ID)
 	})
+}
 
-	return &booking, err
+// reserveTimeSlot marks an open stored time slot booked, failing when a
+// concurrent booking took it first
+func reserveTimeSlot(tx *gorm.DB, slotID uint) error {
+	result := tx.Model(&TimeSlot{}).Where("id = ? AND is_booked = ?", slotID, false).Update("is_booked", true)
+	if result.Error != nil {
+		return errors.New("failed to update slot status")
+	}
+	if result.RowsAffected == 0 {
+		return Conflict("slot_already_booked", "time slot is already booked")
+	}
+	return nil
 }
 
 // GetBookingByID fetches a booking by ID.
 func GetBookingByID(db *gorm.DB, id uint) (*Booking, error) {
 	var booking Booking
-	if err := db.First(&booking, id).Error; err != nil {
-		return nil, errors.New("booking not found")
+	if err := db.Preload("Answers").First(&booking, id).Error; err != nil {
+		return nil, NotFound("booking_not_found", "booking not found")
 	}
 	return &booking, nil
 }
 
-// UpdateBookingStatus changes the booking status and optionally releases the slot.
+// UpdateBookingStatus changes the booking status and, when cancelling, releases
+// the slot. Cancelled bookings stay cancelled, so cancelling twice changes nothing.
 func UpdateBookingStatus(db *gorm.DB, bookingID uint, status string) error {
 	var booking Booking
 	if err := db.First(&booking, bookingID).Error; err != nil {
-		return errors.New("booking not found")
+		return NotFound("booking_not_found", "booking not found")
 	}
 
-	return db.Transaction(func(tx *gorm.DB) error {
-		// Update booking status
-		if err := tx.Model(&booking).Updates(map[string]interface{}{"status": status, "updated_at": time.Now()}).Error; err != nil {
+	changed := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// A cancelled booking stays cancelled; only a booking that actually
+		// changes releases its slot, which may have been booked again since
+		result := tx.Model(&Booking{}).Where("id = ? AND status NOT IN ?", booking.ID, []string{status, "cancelled"}).
+			Updates(map[string]interface{}{"status": status, "updated_at": time.Now()})
+		if result.Error != nil {
 			return errors.New("failed to update booking status")
 		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		changed = true
 
-		// Release slot if booking is cancelled
+		// Release a stored slot and retire the manage link if booking is cancelled
 		if status == "cancelled" {
-			if err := tx.Model(&TimeSlot{}).Where("id = ?", booking.SlotID).Update("is_booked", false).Error; err != nil {
-				return errors.New("failed to release slot")
+			if freedSlotOf(booking).Kind == SlotKindTimeSlot {
+				if err := tx.Model(&TimeSlot{}).Where("id = ?", booking.SlotID).Update("is_booked", false).Error; err != nil {
+					return errors.New("failed to release slot")
+				}
+			}
+			if err := RevokeManageToken(tx, booking.ID); err != nil {
+				return err
+			}
+			if err := CancelBookingEvents(tx, booking.ID); err != nil {
+				return err
 			}
 		}
 
 		return nil
 	})
+	if err != nil || !changed || status != "cancelled" {
+		return err
+	}
+
+	// Offer the released slot to the waitlist before it becomes public again
+	if _, err := OfferFreedSlot(db, freedSlotOf(booking)); err != nil {
+		slog.ErrorContext(db.Statement.Context, "Failed to offer cancelled booking to the waitlist", "booking_id", booking.ID, "error", err)
+	}
+	return nil
+}
+
+// freedSlotOf describes the slot a cancelled booking gives up
+func freedSlotOf(booking Booking) FreedSlot {
+	return FreedSlot{
+		Kind:          booking.SlotKind,
+		SlotID:        booking.SlotID,
+		InterviewerID: booking.InterviewerID,
+		EventTypeID:   booking.EventTypeID,
+		StartTime:     booking.StartTime,
+		EndTime:       booking.EndTime,
+	}
 }
 
 // -------------------- TimeSlot Functions --------------------
 
 // FetchAvailabilityForInterviewer gets available slots for a specific interviewer and date.
+// An interviewerID of 0 covers every interviewer.
 func FetchAvailabilityForInterviewer(db *gorm.DB, interviewerID uint, date string) ([]TimeSlot, error) {
 	startDate, err := time.Parse("2006-01-02", date)
 	if err != nil {
-		return nil, errors.New("invalid date format; expected YYYY-MM-DD")
+		return nil, Validation("invalid_date", "invalid date format; expected YYYY-MM-DD")
 	}
 
-	var timeSlots []TimeSlot
-	err = db.Where("interviewer_id = ? AND start_time >= ? AND end_time <= ? AND is_booked = false",
-		interviewerID, startDate, startDate.Add(24*time.Hour)).Find(&timeSlots).Error
+	query := db.Where("start_time >= ? AND end_time <= ? AND is_booked = false", startDate, startDate.Add(24*time.Hour))
+	if interviewerID != 0 {
+		query = query.Where("interviewer_id = ?", interviewerID)
+	}
+	timeSlots := []TimeSlot{}
+	err = query.Order("start_time, interviewer_id").Find(&timeSlots).Error
 
 	if err != nil {
 		return nil, errors.New("failed to fetch availability")
 	}
-	if len(timeSlots) == 0 {
-		return nil, errors.New("no available slots found for the given date")
-	}
 
-	return timeSlots, nil
+	// Hide slots reserved for waitlisted candidates
+	held, err := HeldSlotIDs(db, SlotKindTimeSlot)
+	if err != nil {
+		return nil, err
+	}
+	open := timeSlots[:0]
+	for _, slot := range timeSlots {
+		if !held[slot.ID] {
+			open = append(open, slot)
+		}
+	}
+	return open, nil
 }
 
-// CreateTimeSlot adds a new timeslot for an interviewer.
-func CreateTimeSlot(db *gorm.DB, interviewerID uint, startTime, endTime time.Time) (*TimeSlot, error) {
-	// Validate times
-	if endTime.Before(startTime) {
-		return nil, errors.New("end time cannot be before start time")
-	}
-	if startTime.Before(time.Now()) {
-		return nil, errors.New("cannot create a timeslot in the past")
+// CreateTimeSlot adds a new timeslot for an interviewer. It may not overlap
+// another slot of the interviewer; with merge set, the open slots it overlaps or
+// touches are folded into it instead.
+func CreateTimeSlot(db *gorm.DB, interviewerID uint, startTime, endTime time.Time, merge bool) (*TimeSlot, error) {
+	if err := ValidateTimeSlot(interviewerID, startTime, endTime); err != nil {
+		return nil, err
 	}
 
 	slot := TimeSlot{
-		InterviewerID: interviewerID,
-		StartTime:     startTime,
-		EndTime:       endTime,
-		IsBooked:      false,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		OrganizationID: CurrentOrganization(db),
+		InterviewerID:  interviewerID,
+		StartTime:      startTime,
+		EndTime:        endTime,
+		IsBooked:       false,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if merge {
+			if err := mergeTimeSlots(tx, &slot); err != nil {
+				return err
+			}
+		} else if err := CheckTimeSlotOverlap(tx, slot.OrganizationID, interviewerID, startTime, endTime, 0); err != nil {
+			return err
+		}
+
+		if err := tx.Create(&slot).Error; err != nil {
+			if err := OverlapConstraintError(err); errors.Is(err, ErrConflict) {
+				return err
+			}
+			return errors.New("failed to create timeslot")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &slot, nil
+}
+
+// UpdateTimeSlot moves an open timeslot, with the same overlap rules as CreateTimeSlot.
+// With ifVersion set, the slot must still be at that version; either way the
+// move fails with ErrPreconditionFailed if the slot changes meanwhile.
+func UpdateTimeSlot(db *gorm.DB, id uint, startTime, endTime time.Time, merge bool, ifVersion uint) (*TimeSlot, error) {
+	var slot TimeSlot
+	if err := db.First(&slot, id).Error; err != nil {
+		return nil, NotFound("slot_not_found", "time slot not found")
+	}
+	if err := checkVersion(slot.Version, ifVersion, "time slot"); err != nil {
+		return nil, err
+	}
+	if slot.IsBooked {
+		return nil, Conflict("slot_already_booked", "a booked time slot cannot be changed")
+	}
+	if err := ValidateTimeSlot(slot.InterviewerID, startTime, endTime); err != nil {
+		return nil, err
 	}
 
-	if err := db.Create(&slot).Error; err != nil {
-		return nil, errors.New("failed to create timeslot")
+	slot.StartTime, slot.EndTime = startTime, endTime
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if merge {
+			if err := mergeTimeSlots(tx, &slot); err != nil {
+				return err
+			}
+		} else if err := CheckTimeSlotOverlap(tx, slot.OrganizationID, slot.InterviewerID, startTime, endTime, slot.ID); err != nil {
+			return err
+		}
+
+		result := tx.Model(&slot).Where("version = ?", slot.Version).Updates(map[string]interface{}{
+			"start_time": slot.StartTime, "end_time": slot.EndTime, "updated_at": time.Now(),
+		})
+		if result.Error != nil {
+			if err := OverlapConstraintError(result.Error); errors.Is(err, ErrConflict) {
+				return err
+			}
+			return errors.New("failed to update timeslot")
+		}
+		if result.RowsAffected == 0 {
+			return staleVersion("time slot")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	slot.Version++
 
 	return &slot, nil
 }
+
+// ValidateTimeSlot applies the rules every new time slot must pass.
+func ValidateTimeSlot(interviewerID uint, startTime, endTime time.Time) error {
+	if interviewerID == 0 {
+		return Validation("interviewer_required", "interviewer is required")
+	}
+	if startTime.IsZero() || endTime.IsZero() {
+		return Validation("times_required", "start time and end time are required")
+	}
+	if !endTime.After(startTime) {
+		return Validation("invalid_time_range", "end time must be after start time")
+	}
+	if startTime.Before(time.Now()) {
+		return PastSlot("slot_in_past", "cannot create a timeslot in the past")
+	}
+	return nil
+}
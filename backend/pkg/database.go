@@ -1,11 +1,10 @@
 package pkg
 
 import (
-	"fmt"
-	"log"
-	"os"
+	"BookingTimeSlot/backend/config"
+	"BookingTimeSlot/backend/logging"
+	"log/slog"
 
-	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -13,39 +12,35 @@ import (
 var DB *gorm.DB
 
 // Connect initializes the database connection and runs auto migrations.
-func Connect() {
-	// Load environment variables from .env file
-	if err := godotenv.Load(); err != nil {
-		log.Fatalf("Error loading .env file: %v", err)
-	}
-
-	// Retrieve database connection details from environment variables
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbName := os.Getenv("DB_NAME")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-
-	// Construct the PostgreSQL connection string
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		dbHost, dbPort, dbUser, dbPassword, dbName)
-
+func Connect(cfg config.Database) {
 	// Attempt to connect to the database
 	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	DB, err = gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{TranslateError: true, Logger: logging.NewQueryLogger(cfg)})
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		logging.Fatal("Failed to connect to database", "error", err)
+	}
+
+	// Scope queries to the request's organization and log every scheduling change
+	if err := RegisterTenantScope(DB); err != nil {
+		logging.Fatal("Failed to register tenant scope", "error", err)
+	}
+	if err := RegisterAuditLog(DB); err != nil {
+		logging.Fatal("Failed to register audit log", "error", err)
+	}
+	if err := RegisterVersioning(DB); err != nil {
+		logging.Fatal("Failed to register versioning", "error", err)
 	}
 
 	// Run all necessary migrations
-	err = DB.AutoMigrate(
-		&Booking{},  // Ensure your models are defined correctly
-		&TimeSlot{}, // Add more models here if needed
-	)
-	if err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+	if err := AutoMigrateTables(DB); err != nil {
+		logging.Fatal("Failed to migrate database", "error", err)
+	}
+	if cfg.OverlapConstraint {
+		if err := EnableOverlapConstraint(DB, TimeSlotOverlapTable); err != nil {
+			logging.Fatal("Failed to enable overlap constraint", "error", err)
+		}
 	}
 
 	// Log a successful connection and migration
-	log.Println("Database connected and migrated successfully")
+	slog.Info("Database connected and migrated successfully")
 }
@@ -0,0 +1,94 @@
+package pkg
+
+import "errors"
+
+// Error kinds. Every domain error wraps one, so callers can branch with errors.Is.
+var (
+	ErrNotFound             = errors.New("not found")
+	ErrConflict             = errors.New("conflict")
+	ErrValidation           = errors.New("validation failed")
+	ErrPastSlot             = errors.New("slot is in the past")
+	ErrGone                 = errors.New("no longer available")
+	ErrUnauthorized         = errors.New("unauthorized")
+	ErrForbidden            = errors.New("forbidden")
+	ErrRateLimited          = errors.New("rate limited")
+	ErrUnprocessable        = errors.New("unprocessable")
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrPreconditionRequired = errors.New("precondition required")
+)
+
+// Error is a domain error with a machine-readable code, e.g. "booking_not_found".
+// Its message is safe to show to API clients.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap makes errors.Is(err, ErrNotFound) and friends work.
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// -------------------- Error Constructors --------------------
+
+// NotFound reports a missing record.
+func NotFound(code, message string) error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: message}
+}
+
+// Conflict reports a request that clashes with the current state, e.g. a taken slot.
+func Conflict(code, message string) error {
+	return &Error{Kind: ErrConflict, Code: code, Message: message}
+}
+
+// Validation reports invalid input.
+func Validation(code, message string) error {
+	return &Error{Kind: ErrValidation, Code: code, Message: message}
+}
+
+// PastSlot reports an attempt to book or create a slot that has already started.
+func PastSlot(code, message string) error {
+	return &Error{Kind: ErrPastSlot, Code: code, Message: message}
+}
+
+// Gone reports a link or offer that has expired or been used.
+func Gone(code, message string) error {
+	return &Error{Kind: ErrGone, Code: code, Message: message}
+}
+
+// Unauthorized reports a missing or invalid credential.
+func Unauthorized(code, message string) error {
+	return &Error{Kind: ErrUnauthorized, Code: code, Message: message}
+}
+
+// Forbidden reports a caller who may not perform the request.
+func Forbidden(code, message string) error {
+	return &Error{Kind: ErrForbidden, Code: code, Message: message}
+}
+
+// RateLimited reports a caller who has made too many requests and should retry later.
+func RateLimited(code, message string) error {
+	return &Error{Kind: ErrRateLimited, Code: code, Message: message}
+}
+
+// Unprocessable reports a well-formed request that cannot be applied, e.g. one
+// reusing an idempotency key sent earlier with a different body.
+func Unprocessable(code, message string) error {
+	return &Error{Kind: ErrUnprocessable, Code: code, Message: message}
+}
+
+// PreconditionFailed reports a write based on a version of a record that has
+// since changed, e.g. an If-Match header naming an old ETag.
+func PreconditionFailed(code, message string) error {
+	return &Error{Kind: ErrPreconditionFailed, Code: code, Message: message}
+}
+
+// PreconditionRequired reports a write that must say which version it is based on.
+func PreconditionRequired(code, message string) error {
+	return &Error{Kind: ErrPreconditionRequired, Code: code, Message: message}
+}
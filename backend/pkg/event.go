@@ -1,16 +1,57 @@
 package pkg
 
 import (
+	"errors"
+	"strings"
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// Event statuses
+const (
+	EventScheduled = "scheduled"
+	EventCancelled = "cancelled"
+	EventCompleted = "completed"
+	EventNoShow    = "no_show" // the candidate did not attend
+)
+
 // Event represents an event that is booked using a time slot
 type Event struct {
 	gorm.Model
-	TimeSlotID  uint   `json:"time_slot_id" gorm:"not null;constraint:OnDelete:CASCADE;"` // Foreign key referencing the time slot
-	BookedBy    string `json:"booked_by" gorm:"not null"`                                 // Email or name of the person who booked the event
-	Title       string `json:"title" gorm:"not null"`                                     // Title of the event
-	Description string `json:"description"`                                               // Description of the event
+	OrganizationID uint      `json:"organization_id" gorm:"index"`
+	BookingID      uint      `json:"booking_id,omitempty" gorm:"index"`                         // Booking that created the event, if any
+	TimeSlotID     uint      `json:"time_slot_id" gorm:"not null;constraint:OnDelete:CASCADE;"` // Foreign key referencing the time slot
+	InterviewerID  uint      `json:"interviewer_id" gorm:"index"`
+	BookedBy       string    `json:"booked_by" gorm:"not null"` // Email or name of the person who booked the event
+	Title          string    `json:"title" gorm:"not null"`     // Title of the event
+	Description    string    `json:"description"`               // Description of the event
+	StartTime      time.Time `json:"start_time" gorm:"index"`
+	EndTime        time.Time `json:"end_time"`
+	Status         string    `json:"status" gorm:"index;default:scheduled"`
+	Version        uint      `json:"version" gorm:"not null;default:1"` // counts changes; see RegisterVersioning
+}
+
+// EventFilter narrows ListEvents. Zero values match everything.
+type EventFilter struct {
+	BookingID     uint
+	InterviewerID uint
+	Candidate     string // part of BookedBy, case-insensitive
+	Status        string
+	From          time.Time // events starting at or after From
+	To            time.Time // events starting before To
+	Limit         int
+	Offset        int
+}
+
+// EventUpdate is a partial update; nil fields are left unchanged.
+type EventUpdate struct {
+	Title       *string
+	Description *string
+	Status      *string
+	StartTime   *time.Time
+	EndTime     *time.Time
+	IfVersion   uint // when set, the update fails unless the event is still at this version
 }
 
 // AutoMigrateEvents initializes the Event table schema in the database
@@ -22,17 +63,23 @@ func AutoMigrateEvents(db *gorm.DB) error {
 	return nil
 }
 
-// CreateEvent creates a new event in the database
-func CreateEvent(db *gorm.DB, timeSlotID uint, bookedBy, title, description string) (*Event, error) {
-	event := Event{
-		TimeSlotID:  timeSlotID,
-		BookedBy:    bookedBy,
-		Title:       title,
-		Description: description,
+// CreateEvent validates and stores a new event. A booking it names must be
+// one of the organization's.
+func CreateEvent(db *gorm.DB, event Event) (*Event, error) {
+	if event.Status == "" {
+		event.Status = EventScheduled
+	}
+	if err := validateEvent(&event); err != nil {
+		return nil, err
+	}
+	if event.BookingID != 0 {
+		if err := db.Select("id").First(&Booking{}, event.BookingID).Error; err != nil {
+			return nil, NotFound("booking_not_found", "booking not found")
+		}
 	}
 
 	if err := db.Create(&event).Error; err != nil {
-		return nil, err
+		return nil, errors.New("failed to create event")
 	}
 	return &event, nil
 }
@@ -41,24 +88,188 @@ func CreateEvent(db *gorm.DB, timeSlotID uint, bookedBy, title, description stri
 func GetEventByID(db *gorm.DB, id uint) (*Event, error) {
 	var event Event
 	if err := db.First(&event, id).Error; err != nil {
-		return nil, err
+		return nil, NotFound("event_not_found", "event not found")
 	}
 	return &event, nil
 }
 
-// UpdateEvent updates the details of an existing event
-func UpdateEvent(db *gorm.DB, id uint, title, description string) (*Event, error) {
-	var event Event
-	if err := db.First(&event, id).Error; err != nil {
+// UpdateEvent applies a partial update to an existing event. It fails with
+// ErrPreconditionFailed if the event changes meanwhile. The times and status
+// of a booking's event follow the booking, which is cancelled or rescheduled
+// instead; only the outcome of its interview, completed or no_show, is set here.
+func UpdateEvent(db *gorm.DB, id uint, update EventUpdate) (*Event, error) {
+	event, err := GetEventByID(db, id)
+	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion(event.Version, update.IfVersion, "event"); err != nil {
+		return nil, err
+	}
+	if event.BookingID != 0 {
+		if update.StartTime != nil || update.EndTime != nil {
+			return nil, Conflict("event_linked_to_booking", "the event belongs to a booking; reschedule the booking to move it")
+		}
+		if update.Status != nil && (event.Status == EventCancelled || *update.Status != EventCompleted && *update.Status != EventNoShow) {
+			return nil, Conflict("event_linked_to_booking", "the event belongs to a booking; cancel the booking instead")
+		}
+	}
 
-	// Update event details
-	event.Title = title
-	event.Description = description
-
-	if err := db.Save(&event).Error; err != nil {
+	changes := map[string]interface{}{}
+	if update.Title != nil {
+		event.Title = *update.Title
+		changes["title"] = event.Title
+	}
+	if update.Description != nil {
+		event.Description = *update.Description
+		changes["description"] = event.Description
+	}
+	if update.Status != nil {
+		event.Status = *update.Status
+		changes["status"] = event.Status
+	}
+	if update.StartTime != nil {
+		event.StartTime = update.StartTime.UTC()
+		changes["start_time"] = event.StartTime
+	}
+	if update.EndTime != nil {
+		event.EndTime = update.EndTime.UTC()
+		changes["end_time"] = event.EndTime
+	}
+	if len(changes) == 0 {
+		return event, nil
+	}
+	if err := validateEvent(event); err != nil {
 		return nil, err
 	}
-	return &event, nil
+
+	changes["updated_at"] = time.Now()
+	result := db.Model(event).Where("version = ?", event.Version).Updates(changes)
+	if result.Error != nil {
+		return nil, errors.New("failed to update event")
+	}
+	if result.RowsAffected == 0 {
+		return nil, staleVersion("event")
+	}
+	event.Version++
+	return event, nil
+}
+
+// ListEvents returns matching events, earliest first, with the total number of matches
+func ListEvents(db *gorm.DB, filter EventFilter) ([]Event, int64, error) {
+	query := db.Model(&Event{})
+	if filter.BookingID != 0 {
+		query = query.Where("booking_id = ?", filter.BookingID)
+	}
+	if filter.InterviewerID != 0 {
+		query = query.Where("interviewer_id = ?", filter.InterviewerID)
+	}
+	if filter.Candidate != "" {
+		query = query.Where("LOWER(booked_by) LIKE ?", "%"+strings.ToLower(filter.Candidate)+"%")
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("start_time >= ?", filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("start_time < ?", filter.To.UTC())
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.New("failed to count events")
+	}
+
+	events := []Event{}
+	if err := query.Order("start_time, id").Limit(filter.Limit).Offset(filter.Offset).Find(&events).Error; err != nil {
+		return nil, 0, errors.New("failed to fetch events")
+	}
+	return events, total, nil
+}
+
+// DeleteEvent soft-deletes an event; with ifVersion set, only if it is still at that version
+func DeleteEvent(db *gorm.DB, id, ifVersion uint) error {
+	query := db.Where("id = ?", id)
+	if ifVersion != 0 {
+		query = query.Where("version = ?", ifVersion)
+	}
+	result := query.Delete(&Event{})
+	if result.Error != nil {
+		return errors.New("failed to delete event")
+	}
+	if result.RowsAffected == 0 {
+		if ifVersion != 0 && db.First(&Event{}, id).Error == nil {
+			return staleVersion("event")
+		}
+		return NotFound("event_not_found", "event not found")
+	}
+	return nil
+}
+
+// validateEvent checks the fields every event needs
+func validateEvent(event *Event) error {
+	if strings.TrimSpace(event.Title) == "" {
+		return Validation("title_required", "title is required")
+	}
+	if strings.TrimSpace(event.BookedBy) == "" {
+		return Validation("booked_by_required", "booked_by is required")
+	}
+	switch event.Status {
+	case EventScheduled, EventCancelled, EventCompleted, EventNoShow:
+	default:
+		return Validation("invalid_status", "status must be scheduled, cancelled, completed or no_show")
+	}
+	if !event.StartTime.IsZero() && !event.EndTime.IsZero() && !event.StartTime.Before(event.EndTime) {
+		return Validation("invalid_time_range", "start time must be before end time")
+	}
+	return nil
+}
+
+// -------------------- Booking Events --------------------
+
+// CreateBookingEvent adds the calendar event of a new booking. Call it in the
+// booking's transaction.
+func CreateBookingEvent(tx *gorm.DB, booking *Booking) error {
+	event := Event{
+		BookingID:     booking.ID,
+		InterviewerID: booking.InterviewerID,
+		BookedBy:      booking.Email,
+		Title:         "Interview with " + booking.Name,
+		StartTime:     booking.StartTime.UTC(),
+		EndTime:       booking.EndTime.UTC(),
+		Status:        EventScheduled,
+	}
+	if booking.SlotKind == SlotKindTimeSlot {
+		event.TimeSlotID = booking.SlotID
+	}
+	if event.BookedBy == "" {
+		event.BookedBy = booking.Name
+	}
+	if err := tx.Create(&event).Error; err != nil {
+		return errors.New("failed to create booking event")
+	}
+	return nil
+}
+
+// CancelBookingEvents marks the scheduled events of a booking cancelled
+func CancelBookingEvents(tx *gorm.DB, bookingID uint) error {
+	err := tx.Model(&Event{}).Where("booking_id = ? AND status = ?", bookingID, EventScheduled).
+		Updates(map[string]interface{}{"status": EventCancelled, "updated_at": time.Now()}).Error
+	if err != nil {
+		return errors.New("failed to cancel booking events")
+	}
+	return nil
+}
+
+// MoveBookingEvents moves the scheduled events of a rescheduled booking
+func MoveBookingEvents(tx *gorm.DB, bookingID, timeSlotID uint, startTime, endTime time.Time) error {
+	err := tx.Model(&Event{}).Where("booking_id = ? AND status = ?", bookingID, EventScheduled).
+		Updates(map[string]interface{}{
+			"time_slot_id": timeSlotID, "start_time": startTime.UTC(), "end_time": endTime.UTC(), "updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		return errors.New("failed to move booking events")
+	}
+	return nil
 }
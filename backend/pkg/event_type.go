@@ -0,0 +1,76 @@
+package pkg
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// EventType describes a kind of interview candidates can book, e.g. "30-minute phone screen".
+type EventType struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID  uint      `json:"organization_id" gorm:"uniqueIndex:idx_event_type_org_slug"`
+	Name            string    `json:"name"`
+	Slug            string    `json:"slug" gorm:"uniqueIndex:idx_event_type_org_slug"`
+	Description     string    `json:"description,omitempty"`
+	DurationMinutes int       `json:"duration_minutes"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// CreateEventType validates and stores a new event type.
+func CreateEventType(db *gorm.DB, name, slug, description string, durationMinutes int) (*EventType, error) {
+	if name == "" {
+		return nil, Validation("name_required", "name is required")
+	}
+	if !slugPattern.MatchString(slug) {
+		return nil, Validation("invalid_slug", "slug must be lowercase letters, digits and dashes")
+	}
+	if durationMinutes <= 0 {
+		return nil, Validation("invalid_duration", "duration minutes must be positive")
+	}
+
+	eventType := EventType{
+		Name:            name,
+		Slug:            slug,
+		Description:     description,
+		DurationMinutes: durationMinutes,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	if err := db.Create(&eventType).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, Conflict("event_type_exists", "an event type with this slug already exists")
+		}
+		return nil, errors.New("failed to create event type")
+	}
+	return &eventType, nil
+}
+
+// ListEventTypes returns every event type ordered by name.
+func ListEventTypes(db *gorm.DB) ([]EventType, error) {
+	var eventTypes []EventType
+	if err := db.Order("name").Find(&eventTypes).Error; err != nil {
+		return nil, errors.New("failed to fetch event types")
+	}
+	return eventTypes, nil
+}
+
+// FindEventType looks an event type up by numeric ID or slug.
+func FindEventType(db *gorm.DB, idOrSlug string) (*EventType, error) {
+	var eventType EventType
+	query := db.Where("slug = ?", idOrSlug)
+	if id, err := strconv.Atoi(idOrSlug); err == nil {
+		query = db.Where("id = ?", id)
+	}
+	if err := query.First(&eventType).Error; err != nil {
+		return nil, NotFound("event_type_not_found", "event type not found")
+	}
+	return &eventType, nil
+}
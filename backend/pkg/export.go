@@ -0,0 +1,250 @@
+package pkg
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Booking export formats
+const (
+	ExportCSV    = "csv"
+	ExportNDJSON = "ndjson" // one JSON booking per line
+	ExportICS    = "ics"
+)
+
+// exportBatchSize is how many bookings an export holds in memory at a time
+const exportBatchSize = 500
+
+// ExportContentTypes maps each export format to its media type.
+var ExportContentTypes = map[string]string{
+	ExportCSV:    "text/csv; charset=utf-8",
+	ExportNDJSON: "application/x-ndjson",
+	ExportICS:    "text/calendar; charset=utf-8",
+}
+
+// BookingExportFilter narrows ExportBookings. Zero values match everything; the
+// organization comes from the tenant scope of db.
+type BookingExportFilter struct {
+	From          time.Time // bookings starting at or after From
+	To            time.Time // bookings starting before To
+	InterviewerID uint
+	Status        string
+}
+
+// bookingEncoder writes one export format
+type bookingEncoder interface {
+	begin() error
+	encode(booking *Booking) error
+	end() error
+}
+
+// ExportBookings streams the matching bookings to w in format, reading them in
+// batches so memory stays bounded however large the export. It returns the
+// number of bookings written. Once writing has started an error leaves the
+// output truncated.
+func ExportBookings(db *gorm.DB, w io.Writer, format string, filter BookingExportFilter) (int, error) {
+	var encoder bookingEncoder
+	switch format {
+	case ExportCSV:
+		encoder = &csvBookingEncoder{writer: csv.NewWriter(w)}
+	case ExportNDJSON:
+		encoder = &ndjsonBookingEncoder{encoder: json.NewEncoder(w)}
+	case ExportICS:
+		encoder = &icsBookingEncoder{w: w, stamp: time.Now().UTC()}
+	default:
+		return 0, Validation("invalid_format", "format must be csv, ndjson or ics")
+	}
+
+	query := db.Model(&Booking{})
+	if !filter.From.IsZero() {
+		query = query.Where("start_time >= ?", filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("start_time < ?", filter.To.UTC())
+	}
+	if filter.InterviewerID != 0 {
+		query = query.Where("interviewer_id = ?", filter.InterviewerID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if err := encoder.begin(); err != nil {
+		return 0, err
+	}
+	count := 0
+	var batch []Booking
+	result := query.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if err := encoder.encode(&batch[i]); err != nil {
+				return err
+			}
+			count++
+		}
+		flushExport(w)
+		return nil
+	})
+	if result.Error != nil {
+		return count, fmt.Errorf("failed to export bookings: %w", result.Error)
+	}
+	if err := encoder.end(); err != nil {
+		return count, err
+	}
+	flushExport(w)
+	return count, nil
+}
+
+// flushExport pushes what is written so far to an HTTP client
+func flushExport(w io.Writer) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// -------------------- CSV --------------------
+
+// csvBookingEncoder writes a header row and one row per booking
+type csvBookingEncoder struct {
+	writer *csv.Writer
+}
+
+func (e *csvBookingEncoder) begin() error {
+	e.writer.Write([]string{"id", "interviewer_id", "name", "email", "status", "slot_kind", "slot_id", "event_type_id", "start_time", "end_time", "booking_date", "created_at"})
+	return e.flush()
+}
+
+func (e *csvBookingEncoder) encode(booking *Booking) error {
+	e.writer.Write([]string{
+		strconv.FormatUint(uint64(booking.ID), 10),
+		strconv.FormatUint(uint64(booking.InterviewerID), 10),
+		booking.Name,
+		booking.Email,
+		booking.Status,
+		booking.SlotKind,
+		strconv.FormatUint(uint64(booking.SlotID), 10),
+		strconv.FormatUint(uint64(booking.EventTypeID), 10),
+		booking.StartTime.UTC().Format(time.RFC3339),
+		booking.EndTime.UTC().Format(time.RFC3339),
+		booking.BookingDate.UTC().Format(time.RFC3339),
+		booking.CreatedAt.UTC().Format(time.RFC3339),
+	})
+	return e.flush()
+}
+
+func (e *csvBookingEncoder) end() error {
+	return e.flush()
+}
+
+func (e *csvBookingEncoder) flush() error {
+	e.writer.Flush()
+	if err := e.writer.Error(); err != nil {
+		return errors.New("failed to write CSV export")
+	}
+	return nil
+}
+
+// -------------------- NDJSON --------------------
+
+// ndjsonBookingEncoder writes each booking as a JSON object on its own line
+type ndjsonBookingEncoder struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonBookingEncoder) begin() error { return nil }
+
+func (e *ndjsonBookingEncoder) encode(booking *Booking) error {
+	if err := e.encoder.Encode(booking); err != nil {
+		return errors.New("failed to write NDJSON export")
+	}
+	return nil
+}
+
+func (e *ndjsonBookingEncoder) end() error { return nil }
+
+// -------------------- ICS --------------------
+
+// icsBookingEncoder writes a VCALENDAR with one VEVENT per booking
+type icsBookingEncoder struct {
+	w     io.Writer
+	stamp time.Time
+}
+
+func (e *icsBookingEncoder) begin() error {
+	return e.lines("BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//BookingTimeSlot//Booking Export//EN", "CALSCALE:GREGORIAN")
+}
+
+func (e *icsBookingEncoder) encode(booking *Booking) error {
+	status := "CONFIRMED"
+	if booking.Status == "cancelled" {
+		status = "CANCELLED"
+	}
+	lines := []string{
+		"BEGIN:VEVENT",
+		fmt.Sprintf("UID:booking-%d@bookingtimeslot", booking.ID),
+		"DTSTAMP:" + formatICSTime(e.stamp),
+		"DTSTART:" + formatICSTime(booking.StartTime),
+		"DTEND:" + formatICSTime(booking.EndTime),
+		"SUMMARY:" + escapeICSText("Interview with "+booking.Name),
+		"STATUS:" + status,
+	}
+	if booking.Email != "" {
+		lines = append(lines, "ATTENDEE;CN="+escapeICSParam(booking.Name)+":mailto:"+booking.Email)
+	}
+	lines = append(lines, "END:VEVENT")
+	return e.lines(lines...)
+}
+
+func (e *icsBookingEncoder) end() error {
+	return e.lines("END:VCALENDAR")
+}
+
+// lines writes content lines with CRLF endings, folded at 75 octets as RFC 5545 requires
+func (e *icsBookingEncoder) lines(lines ...string) error {
+	var b strings.Builder
+	for _, line := range lines {
+		for len(line) > 75 {
+			cut := 75
+			for cut > 0 && !isICSRuneStart(line[cut]) {
+				cut--
+			}
+			b.WriteString(line[:cut])
+			b.WriteString("\r\n ")
+			line = line[cut:]
+		}
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+	if _, err := io.WriteString(e.w, b.String()); err != nil {
+		return errors.New("failed to write ICS export")
+	}
+	return nil
+}
+
+// isICSRuneStart reports whether a fold may happen before byte c without splitting a UTF-8 sequence
+func isICSRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+// formatICSTime formats a UTC DATE-TIME, e.g. 20250101T090000Z
+func formatICSTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeICSText escapes a TEXT value
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// escapeICSParam quotes a parameter value, which may not contain double quotes
+func escapeICSParam(s string) string {
+	return `"` + strings.NewReplacer(`"`, "'", "\r", "", "\n", " ").Replace(s) + `"`
+}
@@ -0,0 +1,243 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultHolidayCalendar is the calendar name used for the bundled holiday file.
+const DefaultHolidayCalendar = "default"
+
+//go:embed holidays.csv
+var bundledHolidays []byte
+
+// Holiday is an organization-wide day on which no interviewer is available.
+type Holiday struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"uniqueIndex:idx_holiday_org_calendar_date"`
+	Calendar       string    `json:"calendar" gorm:"uniqueIndex:idx_holiday_org_calendar_date"`
+	Date           string    `json:"date" gorm:"uniqueIndex:idx_holiday_org_calendar_date;index"` // YYYY-MM-DD
+	Name           string    `json:"name"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// -------------------- Holiday Functions --------------------
+
+// LoadBundledHolidays stores the holiday file shipped with the server under the
+// default calendar of the session's organization.
+func LoadBundledHolidays(db *gorm.DB) (int, error) {
+	holidays, err := ParseHolidaysCSV(bytes.NewReader(bundledHolidays))
+	if err != nil {
+		return 0, err
+	}
+	return SaveHolidays(db, DefaultHolidayCalendar, holidays)
+}
+
+// SaveHolidays upserts holidays into a named calendar and returns how many were stored.
+func SaveHolidays(db *gorm.DB, calendar string, holidays []Holiday) (int, error) {
+	if calendar == "" {
+		return 0, Validation("calendar_required", "calendar name is required")
+	}
+	if len(holidays) == 0 {
+		return 0, nil
+	}
+
+	// A date may only appear once per upsert batch; the last entry wins
+	now := time.Now()
+	byDate := make(map[string]int, len(holidays))
+	unique := make([]Holiday, 0, len(holidays))
+	for _, holiday := range holidays {
+		holiday.Calendar = calendar
+		holiday.CreatedAt = now
+		holiday.UpdatedAt = now
+		if i, ok := byDate[holiday.Date]; ok {
+			unique[i] = holiday
+			continue
+		}
+		byDate[holiday.Date] = len(unique)
+		unique = append(unique, holiday)
+	}
+
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "calendar"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "updated_at"}),
+	}).Create(&unique).Error
+	if err != nil {
+		return 0, errors.New("failed to save holidays")
+	}
+	return len(unique), nil
+}
+
+// ListHolidays returns holidays from every calendar, optionally limited to a date range.
+func ListHolidays(db *gorm.DB, from, to string) ([]Holiday, error) {
+	query := db.Model(&Holiday{})
+	if from != "" {
+		query = query.Where("date >= ?", from)
+	}
+	if to != "" {
+		query = query.Where("date <= ?", to)
+	}
+
+	var holidays []Holiday
+	if err := query.Order("date").Find(&holidays).Error; err != nil {
+		return nil, errors.New("failed to fetch holidays")
+	}
+	return holidays, nil
+}
+
+// FindHoliday returns the holiday on a date from any calendar, or nil if the date is a normal day.
+func FindHoliday(db *gorm.DB, date string) (*Holiday, error) {
+	var holidays []Holiday
+	if err := db.Where("date = ?", date).Limit(1).Find(&holidays).Error; err != nil {
+		return nil, errors.New("failed to fetch holidays")
+	}
+	if len(holidays) == 0 {
+		return nil, nil
+	}
+	return &holidays[0], nil
+}
+
+// -------------------- Parsers --------------------
+
+// ParseHolidaysCSV reads "date,name" rows. A header row and blank lines are skipped.
+func ParseHolidaysCSV(r io.Reader) ([]Holiday, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	var holidays []Holiday
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, Validation("invalid_holiday_file", fmt.Sprintf("invalid CSV on line %d: %v", line, err))
+		}
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "date") {
+			continue
+		}
+
+		date := strings.TrimSpace(record[0])
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, Validation("invalid_holiday_file", fmt.Sprintf("invalid date %q on line %d; expected YYYY-MM-DD", date, line))
+		}
+
+		name := ""
+		if len(record) > 1 {
+			name = strings.TrimSpace(record[1])
+		}
+		holidays = append(holidays, Holiday{Date: date, Name: name})
+	}
+
+	return holidays, nil
+}
+
+// ParseHolidaysICS reads all-day VEVENTs from an iCalendar file. Multi-day events
+// produce one holiday per day; DTEND is exclusive as in RFC 5545.
+func ParseHolidaysICS(r io.Reader) ([]Holiday, error) {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var holidays []Holiday
+	var inEvent bool
+	var start, end time.Time
+	var summary string
+
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		property, _, _ := strings.Cut(name, ";")
+
+		switch strings.ToUpper(property) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				inEvent = true
+				start, end, summary = time.Time{}, time.Time{}, ""
+			}
+		case "END":
+			if !strings.EqualFold(value, "VEVENT") || !inEvent {
+				continue
+			}
+			inEvent = false
+			if start.IsZero() {
+				return nil, Validation("invalid_holiday_file", "invalid ICS: event without DTSTART")
+			}
+			if end.IsZero() || !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+				holidays = append(holidays, Holiday{Date: day.Format("2006-01-02"), Name: summary})
+			}
+		case "DTSTART", "DTEND":
+			if !inEvent {
+				continue
+			}
+			day, err := parseICSDate(value)
+			if err != nil {
+				return nil, err
+			}
+			if strings.EqualFold(property, "DTSTART") {
+				start = day
+			} else {
+				end = day
+			}
+		case "SUMMARY":
+			if inEvent {
+				summary = strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\\`, `\`).Replace(value)
+			}
+		}
+	}
+
+	return holidays, nil
+}
+
+// unfoldICSLines joins continuation lines, which start with a space or tab.
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ICS: %v", err)
+	}
+	return lines, nil
+}
+
+// parseICSDate accepts DATE (20250101) and DATE-TIME (20250101T090000Z) values and keeps only the day.
+func parseICSDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 8 {
+		return time.Time{}, Validation("invalid_holiday_file", fmt.Sprintf("invalid ICS date %q", value))
+	}
+	day, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, Validation("invalid_holiday_file", fmt.Sprintf("invalid ICS date %q", value))
+	}
+	return day, nil
+}
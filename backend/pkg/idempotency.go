@@ -0,0 +1,109 @@
+package pkg
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyWindow is how long a finished request's response is kept for
+// retries with the same Idempotency-Key.
+var IdempotencyWindow = 24 * time.Hour
+
+// idempotencyAbandonedAfter is when a key whose request never finished, e.g.
+// because the server crashed, may be claimed again. It outlasts the HTTP write
+// timeout, so a request still running keeps its key.
+const idempotencyAbandonedAfter = 5 * time.Minute
+
+// IdempotencyKey records a mutating request sent with an Idempotency-Key header
+// and, once it finished, the response to replay when the request is retried.
+type IdempotencyKey struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Scope       string    `json:"scope" gorm:"uniqueIndex:idx_idempotency_key"` // organization and caller, e.g. "2:anonymous:203.0.113.7"
+	Key         string    `json:"key" gorm:"size:255;uniqueIndex:idx_idempotency_key"`
+	RequestHash string    `json:"request_hash"` // of the method, path and body
+	StatusCode  int       `json:"status_code"`  // 0 while the request is in progress
+	ContentType string    `json:"content_type"`
+	Body        []byte    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"index"`
+}
+
+// Finished reports whether the key holds a response to replay
+func (k *IdempotencyKey) Finished() bool {
+	return k.StatusCode != 0
+}
+
+// ClaimIdempotencyKey records that the request with key is in progress. If the
+// key was used before, it returns that earlier record when it has finished
+// with the same request hash, and an error when it is still in progress or
+// was sent with a different request.
+func ClaimIdempotencyKey(db *gorm.DB, scope, key, requestHash string) (*IdempotencyKey, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		now := time.Now()
+		record := IdempotencyKey{Scope: scope, Key: key, RequestHash: requestHash, CreatedAt: now, ExpiresAt: now.Add(IdempotencyWindow)}
+		err := db.Create(&record).Error
+		if err == nil {
+			return &record, nil
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, errors.New("failed to save idempotency key")
+		}
+
+		var existing IdempotencyKey
+		if err := db.Where("scope = ? AND key = ?", scope, key).First(&existing).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue // released since
+			}
+			return nil, errors.New("failed to fetch idempotency key")
+		}
+
+		expired := !existing.ExpiresAt.After(now)
+		abandoned := !existing.Finished() && existing.CreatedAt.Before(now.Add(-idempotencyAbandonedAfter))
+		switch {
+		case expired || abandoned:
+			// Start over; the condition keeps a concurrent retry from deleting a fresh claim
+			if err := db.Where("id = ? AND created_at = ?", existing.ID, existing.CreatedAt).Delete(&IdempotencyKey{}).Error; err != nil {
+				return nil, errors.New("failed to release idempotency key")
+			}
+		case existing.RequestHash != requestHash:
+			return nil, Unprocessable("idempotency_key_reused", "Idempotency-Key was already used for a different request")
+		case !existing.Finished():
+			return nil, Conflict("idempotency_key_in_progress", "A request with this Idempotency-Key is still in progress")
+		default:
+			return &existing, nil
+		}
+	}
+	return nil, Conflict("idempotency_key_in_progress", "A request with this Idempotency-Key is still in progress")
+}
+
+// SaveIdempotentResponse stores the response to a claimed key's request and
+// keeps it for IdempotencyWindow.
+func SaveIdempotentResponse(db *gorm.DB, record *IdempotencyKey, statusCode int, contentType string, body []byte) error {
+	expiresAt := time.Now().Add(IdempotencyWindow)
+	err := db.Model(&IdempotencyKey{}).Where("id = ?", record.ID).
+		Updates(map[string]interface{}{"status_code": statusCode, "content_type": contentType, "body": body, "expires_at": expiresAt}).Error
+	if err != nil {
+		return errors.New("failed to save idempotent response")
+	}
+	record.StatusCode, record.ContentType, record.Body, record.ExpiresAt = statusCode, contentType, body, expiresAt
+	return nil
+}
+
+// ReleaseIdempotencyKey forgets a claimed key, so a retry runs the request again.
+func ReleaseIdempotencyKey(db *gorm.DB, record *IdempotencyKey) error {
+	if err := db.Where("id = ?", record.ID).Delete(&IdempotencyKey{}).Error; err != nil {
+		return errors.New("failed to release idempotency key")
+	}
+	return nil
+}
+
+// PurgeIdempotencyKeys deletes keys whose window has ended.
+func PurgeIdempotencyKeys(db *gorm.DB) (int64, error) {
+	result := db.Where("expires_at <= ?", time.Now()).Delete(&IdempotencyKey{})
+	if result.Error != nil {
+		return 0, errors.New("failed to purge idempotency keys")
+	}
+	return result.RowsAffected, nil
+}
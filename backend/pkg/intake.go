@@ -0,0 +1,325 @@
+package pkg
+
+import (
+	"BookingTimeSlot/backend/notifications"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Intake question types
+const (
+	QuestionText     = "text"
+	QuestionEmail    = "email"
+	QuestionPhone    = "phone"
+	QuestionURL      = "url"
+	QuestionNumber   = "number"
+	QuestionSelect   = "select"
+	QuestionCheckbox = "checkbox" // e.g. consent; a required checkbox must be "true"
+)
+
+var (
+	questionKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	phonePattern       = regexp.MustCompile(`^\+?[0-9 ()\-.]{7,20}$`)
+	questionTypes      = map[string]bool{
+		QuestionText: true, QuestionEmail: true, QuestionPhone: true, QuestionURL: true,
+		QuestionNumber: true, QuestionSelect: true, QuestionCheckbox: true,
+	}
+)
+
+// -------------------- Models --------------------
+
+// IntakeQuestion is a custom booking form field attached to an event type.
+type IntakeQuestion struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"index"`
+	EventTypeID    uint      `json:"event_type_id" gorm:"uniqueIndex:idx_question_event_type_key"`
+	Key            string    `json:"key" gorm:"uniqueIndex:idx_question_event_type_key"` // answer field name, e.g. "resume_link"
+	Label          string    `json:"label"`
+	Type           string    `json:"type"`
+	Required       bool      `json:"required"`
+	Options        []string  `json:"options,omitempty" gorm:"serializer:json"` // choices for select questions
+	Pattern        string    `json:"pattern,omitempty"`                        // optional extra regular expression
+	MaxLength      int       `json:"max_length,omitempty"`
+	Position       int       `json:"position"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// BookingAnswer stores a candidate's answer to an intake question with the booking.
+type BookingAnswer struct {
+	ID             uint      `json:"-" gorm:"primaryKey"`
+	OrganizationID uint      `json:"-" gorm:"index"`
+	BookingID      uint      `json:"-" gorm:"index"`
+	QuestionID     uint      `json:"question_id"`
+	Key            string    `json:"key"`
+	Label          string    `json:"label"`
+	Value          string    `json:"value"`
+	CreatedAt      time.Time `json:"-"`
+}
+
+// AnswerErrors lists every invalid answer, keyed by question key.
+type AnswerErrors map[string]string
+
+func (e AnswerErrors) Error() string {
+	keys := make([]string, 0, len(e))
+	for key := range e {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	messages := make([]string, 0, len(keys))
+	for _, key := range keys {
+		messages = append(messages, key+": "+e[key])
+	}
+	return "invalid answers: " + strings.Join(messages, "; ")
+}
+
+// Unwrap makes invalid answers a validation error.
+func (e AnswerErrors) Unwrap() error {
+	return ErrValidation
+}
+
+// -------------------- Question Functions --------------------
+
+// CreateIntakeQuestion validates and stores a question for an event type.
+func CreateIntakeQuestion(db *gorm.DB, question IntakeQuestion) (*IntakeQuestion, error) {
+	if question.EventTypeID == 0 {
+		return nil, Validation("event_type_required", "event type is required")
+	}
+	if !questionKeyPattern.MatchString(question.Key) {
+		return nil, Validation("invalid_question_key", "key must start with a letter and contain only lowercase letters, digits and underscores")
+	}
+	if question.Label == "" {
+		return nil, Validation("label_required", "label is required")
+	}
+	if !questionTypes[question.Type] {
+		return nil, Validation("invalid_question_type", fmt.Sprintf("unsupported question type %q", question.Type))
+	}
+	if question.Type == QuestionSelect && len(question.Options) == 0 {
+		return nil, Validation("options_required", "select questions need at least one option")
+	}
+	if question.Pattern != "" {
+		if _, err := regexp.Compile(question.Pattern); err != nil {
+			return nil, Validation("invalid_pattern", "invalid pattern")
+		}
+	}
+	if question.MaxLength < 0 {
+		return nil, Validation("invalid_max_length", "max length must not be negative")
+	}
+
+	var eventType EventType
+	if err := db.First(&eventType, question.EventTypeID).Error; err != nil {
+		return nil, NotFound("event_type_not_found", "event type not found")
+	}
+
+	question.ID = 0
+	question.CreatedAt = time.Now()
+	question.UpdatedAt = time.Now()
+	if err := db.Create(&question).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, Conflict("question_exists", "a question with this key already exists")
+		}
+		return nil, errors.New("failed to create intake question")
+	}
+	return &question, nil
+}
+
+// ListIntakeQuestions returns an event type's questions in display order.
+func ListIntakeQuestions(db *gorm.DB, eventTypeID uint) ([]IntakeQuestion, error) {
+	var questions []IntakeQuestion
+	if err := db.Where("event_type_id = ?", eventTypeID).Order("position, id").Find(&questions).Error; err != nil {
+		return nil, errors.New("failed to fetch intake questions")
+	}
+	return questions, nil
+}
+
+// DeleteIntakeQuestion removes a question. Answers already given keep their copy of the label.
+func DeleteIntakeQuestion(db *gorm.DB, id uint) error {
+	result := db.Delete(&IntakeQuestion{}, id)
+	if result.Error != nil {
+		return errors.New("failed to delete intake question")
+	}
+	if result.RowsAffected == 0 {
+		return NotFound("question_not_found", "intake question not found")
+	}
+	return nil
+}
+
+// -------------------- Answer Functions --------------------
+
+// ValidateAnswers checks answers against an event type's questions and returns them
+// ready to be stored. Without an event type no answers are accepted.
+func ValidateAnswers(db *gorm.DB, eventTypeID uint, answers map[string]string) ([]BookingAnswer, error) {
+	if eventTypeID == 0 {
+		if len(answers) > 0 {
+			return nil, Validation("invalid_answers", "answers require an event type")
+		}
+		return nil, nil
+	}
+
+	questions, err := ListIntakeQuestions(db, eventTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := AnswerErrors{}
+	known := make(map[string]bool, len(questions))
+	validated := []BookingAnswer{}
+
+	for _, question := range questions {
+		known[question.Key] = true
+		value := strings.TrimSpace(answers[question.Key])
+
+		if value == "" || (question.Type == QuestionCheckbox && value == "false") {
+			if question.Required {
+				problems[question.Key] = "is required"
+			}
+			continue
+		}
+		if message := validateAnswer(question, value); message != "" {
+			problems[question.Key] = message
+			continue
+		}
+
+		validated = append(validated, BookingAnswer{
+			QuestionID: question.ID,
+			Key:        question.Key,
+			Label:      question.Label,
+			Value:      value,
+			CreatedAt:  time.Now(),
+		})
+	}
+
+	for key := range answers {
+		if !known[key] {
+			problems[key] = "is not a question for this event type"
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return validated, nil
+}
+
+// validateAnswer returns a description of what is wrong with a non-empty value, or "".
+func validateAnswer(question IntakeQuestion, value string) string {
+	if question.MaxLength > 0 && len(value) > question.MaxLength {
+		return fmt.Sprintf("must be at most %d characters", question.MaxLength)
+	}
+
+	switch question.Type {
+	case QuestionEmail:
+		if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+			return "must be a valid email address"
+		}
+	case QuestionPhone:
+		if !phonePattern.MatchString(value) {
+			return "must be a valid phone number"
+		}
+	case QuestionURL:
+		if parsed, err := url.ParseRequestURI(value); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return "must be an http or https URL"
+		}
+	case QuestionNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "must be a number"
+		}
+	case QuestionSelect:
+		valid := false
+		for _, option := range question.Options {
+			if option == value {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return "must be one of: " + strings.Join(question.Options, ", ")
+		}
+	case QuestionCheckbox:
+		if value != "true" {
+			return `must be "true" or "false"`
+		}
+	}
+
+	if question.Pattern != "" {
+		if matched, err := regexp.MatchString(question.Pattern, value); err != nil || !matched {
+			return "has an invalid format"
+		}
+	}
+	return ""
+}
+
+// SaveBookingAnswers stores validated answers for a booking.
+func SaveBookingAnswers(db *gorm.DB, bookingID uint, answers []BookingAnswer) error {
+	if len(answers) == 0 {
+		return nil
+	}
+	for i := range answers {
+		answers[i].ID = 0
+		answers[i].BookingID = bookingID
+	}
+	if err := db.Create(&answers).Error; err != nil {
+		return errors.New("failed to save booking answers")
+	}
+	return nil
+}
+
+// GetBookingAnswers returns the answers given with a booking.
+func GetBookingAnswers(db *gorm.DB, bookingID uint) ([]BookingAnswer, error) {
+	var answers []BookingAnswer
+	if err := db.Where("booking_id = ?", bookingID).Order("id").Find(&answers).Error; err != nil {
+		return nil, errors.New("failed to fetch booking answers")
+	}
+	return answers, nil
+}
+
+// AnswersByBooking returns answers for several bookings, keyed by booking ID.
+func AnswersByBooking(db *gorm.DB, bookingIDs []uint) (map[uint][]BookingAnswer, error) {
+	byBooking := map[uint][]BookingAnswer{}
+	if len(bookingIDs) == 0 {
+		return byBooking, nil
+	}
+
+	var answers []BookingAnswer
+	if err := db.Where("booking_id IN ?", bookingIDs).Order("id").Find(&answers).Error; err != nil {
+		return nil, errors.New("failed to fetch booking answers")
+	}
+	for _, answer := range answers {
+		byBooking[answer.BookingID] = append(byBooking[answer.BookingID], answer)
+	}
+	return byBooking, nil
+}
+
+// NotifyInterviewerOfBooking emails the interviewer the booking details and intake answers.
+// Interviewers without a stored email address are skipped.
+func NotifyInterviewerOfBooking(db *gorm.DB, interviewerID uint, candidateName, candidateEmail string, start time.Time, answers []BookingAnswer) {
+	var interviewer Interviewer
+	if err := db.First(&interviewer, interviewerID).Error; err != nil || interviewer.Email == "" {
+		return
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hi %s,\n\n%s <%s> booked an interview with you", interviewer.Name, candidateName, candidateEmail)
+	if !start.IsZero() {
+		fmt.Fprintf(&body, " on %s", start.Format(time.RFC1123))
+	}
+	body.WriteString(".\n")
+	if len(answers) > 0 {
+		body.WriteString("\nCandidate answers:\n")
+		for _, answer := range answers {
+			fmt.Fprintf(&body, "- %s: %s\n", answer.Label, answer.Value)
+		}
+	}
+
+	notifications.Enqueue(db.Statement.Context, interviewer.Email, "New interview booking: "+candidateName, body.String())
+}
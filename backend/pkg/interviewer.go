@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Interviewer is a person whose time candidates book. Other models refer to it by InterviewerID.
+type Interviewer struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"uniqueIndex:idx_interviewer_org_email"`
+	Name           string    `json:"name"`
+	Email          string    `json:"email" gorm:"uniqueIndex:idx_interviewer_org_email"`
+	TimeZone       string    `json:"time_zone,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// CreateInterviewer validates and stores an interviewer.
+func CreateInterviewer(db *gorm.DB, name, email, timeZone string) (*Interviewer, error) {
+	if name == "" || email == "" {
+		return nil, Validation("name_and_email_required", "name and email are required")
+	}
+	if timeZone != "" {
+		if _, err := time.LoadLocation(timeZone); err != nil {
+			return nil, Validation("invalid_time_zone", "invalid time zone")
+		}
+	}
+
+	interviewer := Interviewer{
+		Name:      name,
+		Email:     email,
+		TimeZone:  timeZone,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := db.Create(&interviewer).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, Conflict("interviewer_exists", "an interviewer with this email already exists")
+		}
+		return nil, errors.New("failed to create interviewer")
+	}
+	return &interviewer, nil
+}
+
+// ListInterviewers returns every interviewer ordered by name.
+func ListInterviewers(db *gorm.DB) ([]Interviewer, error) {
+	var interviewers []Interviewer
+	if err := db.Order("name").Find(&interviewers).Error; err != nil {
+		return nil, errors.New("failed to fetch interviewers")
+	}
+	return interviewers, nil
+}
+
+// GetInterviewerByID fetches an interviewer by ID.
+func GetInterviewerByID(db *gorm.DB, id uint) (*Interviewer, error) {
+	var interviewer Interviewer
+	if err := db.First(&interviewer, id).Error; err != nil {
+		return nil, NotFound("interviewer_not_found", "interviewer not found")
+	}
+	return &interviewer, nil
+}
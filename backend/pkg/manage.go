@@ -0,0 +1,197 @@
+package pkg
+
+import (
+	"BookingTimeSlot/backend/notifications"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ManageBookingBaseURL prefixes the manage link emailed to candidates.
+var ManageBookingBaseURL = "http://localhost:3000/manage"
+
+// -------------------- Manage Token Functions --------------------
+
+// IssueManageToken gives a booking a new unguessable manage token valid until expiresAt.
+// Only a hash is stored; the returned token is the only copy.
+func IssueManageToken(db *gorm.DB, bookingID uint, expiresAt time.Time) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	hash := hashToken(token)
+	result := db.Model(&Booking{}).Where("id = ?", bookingID).
+		Updates(map[string]interface{}{"manage_token": hash, "manage_expires": expiresAt})
+	if result.Error != nil {
+		return "", errors.New("failed to save manage token")
+	}
+	if result.RowsAffected == 0 {
+		return "", NotFound("booking_not_found", "booking not found")
+	}
+	return token, nil
+}
+
+// SendManageLink issues a manage token and emails the link to the candidate.
+// Failures are logged; a missing link never fails the booking itself.
+func SendManageLink(db *gorm.DB, bookingID uint, name, email string, start, end time.Time) {
+	if email == "" {
+		return
+	}
+
+	token, err := IssueManageToken(db, bookingID, end)
+	if err != nil {
+		slog.ErrorContext(db.Statement.Context, "Failed to issue manage token", "booking_id", bookingID, "error", err)
+		return
+	}
+
+	notifications.Enqueue(db.Statement.Context, email, "Your interview is booked", fmt.Sprintf(
+		"Hi %s,\n\nYour interview on %s is confirmed.\nTo view, cancel or reschedule it, use this link: %s?token=%s\n\nThe link stops working once the interview is over.\n",
+		name, start.Format(time.RFC1123), ManageBookingBaseURL, token))
+}
+
+// FindBookingByManageToken returns the booking a manage token belongs to.
+func FindBookingByManageToken(db *gorm.DB, token string) (*Booking, error) {
+	if token == "" {
+		return nil, NotFound("booking_not_found", "booking not found")
+	}
+
+	var booking Booking
+	if err := db.Preload("Answers").Where("manage_token = ?", hashToken(token)).First(&booking).Error; err != nil {
+		return nil, NotFound("booking_not_found", "booking not found")
+	}
+	if booking.ManageExpires == nil || !booking.ManageExpires.After(time.Now()) {
+		return nil, Gone("manage_link_expired", "manage link has expired")
+	}
+	return &booking, nil
+}
+
+// RevokeManageToken makes a booking's manage link stop working.
+func RevokeManageToken(db *gorm.DB, bookingID uint) error {
+	if err := db.Model(&Booking{}).Where("id = ?", bookingID).Update("manage_token", nil).Error; err != nil {
+		return errors.New("failed to revoke manage token")
+	}
+	return nil
+}
+
+// -------------------- Reschedule Functions --------------------
+
+// RescheduleBooking moves a schedule booking to a new offered interval, or a
+// time-slot booking to another free TimeSlot, then offers the old slot to the waitlist.
+func RescheduleBooking(db *gorm.DB, bookingID uint, newSlotID uint, startTime, endTime time.Time) (*Booking, error) {
+	booking, err := GetBookingByID(db, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.Status == "cancelled" {
+		return nil, Conflict("booking_cancelled", "booking is cancelled")
+	}
+	freed := FreedSlot{
+		Kind:          SlotKindSchedule,
+		SlotID:        booking.SlotID,
+		InterviewerID: booking.InterviewerID,
+		EventTypeID:   booking.EventTypeID,
+		StartTime:     booking.StartTime,
+		EndTime:       booking.EndTime,
+	}
+
+	switch booking.SlotKind {
+	case SlotKindTimeSlot:
+		freed.Kind = SlotKindTimeSlot
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var slot TimeSlot
+			if err := tx.First(&slot, newSlotID).Error; err != nil {
+				return NotFound("slot_not_found", "time slot not found")
+			}
+			if slot.StartTime.Before(time.Now()) {
+				return PastSlot("slot_in_past", "cannot book a past time slot")
+			}
+			held, err := HeldSlotIDs(tx, SlotKindTimeSlot)
+			if err != nil {
+				return err
+			}
+			if held[slot.ID] {
+				return Conflict("slot_held", "time slot is held for a waitlisted candidate")
+			}
+
+			result := tx.Model(&TimeSlot{}).Where("id = ? AND is_booked = ?", slot.ID, false).Update("is_booked", true)
+			if result.Error != nil {
+				return errors.New("failed to update slot status")
+			}
+			if result.RowsAffected == 0 {
+				return Conflict("slot_already_booked", "time slot is already booked")
+			}
+			if err := tx.Model(&TimeSlot{}).Where("id = ?", booking.SlotID).Update("is_booked", false).Error; err != nil {
+				return errors.New("failed to release slot")
+			}
+
+			err = tx.Model(booking).Updates(map[string]interface{}{
+				"slot_id": slot.ID, "interviewer_id": slot.InterviewerID,
+				"start_time": slot.StartTime, "end_time": slot.EndTime,
+				"manage_expires": slot.EndTime, "updated_at": time.Now(),
+			}).Error
+			if err != nil {
+				return errors.New("failed to update booking")
+			}
+			return MoveBookingEvents(tx, booking.ID, slot.ID, slot.StartTime, slot.EndTime)
+		})
+
+	case SlotKindSchedule:
+		if startTime.IsZero() || !startTime.Before(endTime) {
+			return nil, Validation("invalid_time_range", "a valid start time and end time are required")
+		}
+		status := booking.Status
+		err = db.Transaction(func(tx *gorm.DB) error {
+			// Step out of the way so the booking's own interval counts as free
+			if err := tx.Model(&Booking{}).Where("id = ?", booking.ID).Update("status", "cancelled").Error; err != nil {
+				return errors.New("failed to update booking")
+			}
+			offered, err := IsSlotOffered(tx, booking.InterviewerID, startTime, endTime)
+			if err != nil {
+				return err
+			}
+			if !offered {
+				return Validation("slot_unavailable", "requested time is not an available slot")
+			}
+
+			err = tx.Model(booking).Updates(map[string]interface{}{
+				"status": status, "start_time": startTime.UTC(), "end_time": endTime.UTC(),
+				"manage_expires": endTime.UTC(), "updated_at": time.Now(),
+			}).Error
+			if err != nil {
+				return errors.New("failed to update booking")
+			}
+			return MoveBookingEvents(tx, booking.ID, 0, startTime, endTime)
+		})
+
+	default:
+		return nil, Conflict("reschedule_not_supported", "this booking cannot be rescheduled here")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A move within the old interval frees nothing bookable
+	updated, err := GetBookingByID(db, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if freed.Kind == SlotKindSchedule && updated.StartTime.Before(freed.EndTime) && updated.EndTime.After(freed.StartTime) {
+		return updated, nil
+	}
+	if _, err := OfferFreedSlot(db, freed); err != nil {
+		slog.ErrorContext(db.Statement.Context, "Failed to offer rescheduled booking's old slot to the waitlist", "booking_id", booking.ID, "error", err)
+	}
+	return updated, nil
+}
+
+// hashToken returns the hex SHA-256 of a token as stored in the database.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
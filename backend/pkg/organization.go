@@ -0,0 +1,128 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultOrganizationSlug names the organization that owns data created before tenants existed.
+const DefaultOrganizationSlug = "default"
+
+// tenantTables lists the tables of pkg models scoped to an organization.
+var tenantTables = []string{
+	"time_slots", "bookings", "events", "availability_overrides", "schedule_templates", "event_types",
+	"waitlist_entries", "waitlist_offers", "interviewers", "intake_questions", "booking_answers", "holidays",
+}
+
+// Organization is a tenant, e.g. a business unit. Tenants never see each other's data.
+type Organization struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug" gorm:"uniqueIndex"` // subdomain, e.g. "acme" for acme.example.com
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// -------------------- Organization Functions --------------------
+
+// CreateOrganization validates and stores a new organization.
+func CreateOrganization(db *gorm.DB, name, slug string) (*Organization, error) {
+	if name == "" {
+		return nil, Validation("name_required", "name is required")
+	}
+	if !slugPattern.MatchString(slug) {
+		return nil, Validation("invalid_slug", "slug must be lowercase letters, digits and dashes")
+	}
+
+	organization := Organization{
+		Name:      name,
+		Slug:      slug,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := db.Create(&organization).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, Conflict("organization_exists", "an organization with this slug already exists")
+		}
+		return nil, errors.New("failed to create organization")
+	}
+	return &organization, nil
+}
+
+// ListOrganizations returns every organization ordered by name.
+func ListOrganizations(db *gorm.DB) ([]Organization, error) {
+	var organizations []Organization
+	if err := db.Order("name").Find(&organizations).Error; err != nil {
+		return nil, errors.New("failed to fetch organizations")
+	}
+	return organizations, nil
+}
+
+// FindOrganization looks an organization up by numeric ID or slug.
+func FindOrganization(db *gorm.DB, idOrSlug string) (*Organization, error) {
+	var organizations []Organization
+	query := db.Where("slug = ?", idOrSlug)
+	if id, err := strconv.Atoi(idOrSlug); err == nil {
+		query = db.Where("id = ?", id)
+	}
+	if err := query.Limit(1).Find(&organizations).Error; err != nil {
+		return nil, errors.New("failed to fetch organization")
+	}
+	if len(organizations) == 0 {
+		return nil, NotFound("organization_not_found", "organization not found")
+	}
+	return &organizations[0], nil
+}
+
+// EnsureDefaultOrganization returns the default organization, creating it if needed.
+func EnsureDefaultOrganization(db *gorm.DB) (*Organization, error) {
+	organization := Organization{Name: "Default", Slug: DefaultOrganizationSlug, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&organization).Error; err != nil {
+		return nil, errors.New("failed to create default organization")
+	}
+	return FindOrganization(db, DefaultOrganizationSlug)
+}
+
+// AssignDefaultOrganization moves rows that belong to no organization into the default one.
+func AssignDefaultOrganization(db *gorm.DB, tables ...string) error {
+	organization, err := EnsureDefaultOrganization(db)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		err := db.Table(table).Where("organization_id = 0 OR organization_id IS NULL").
+			Update("organization_id", organization.ID).Error
+		if err != nil {
+			return fmt.Errorf("failed to assign %s to the default organization: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// migrateTenantIndexes drops unique indexes that predate organizations; AutoMigrate
+// recreates them per organization.
+func migrateTenantIndexes(db *gorm.DB) error {
+	legacy := []struct {
+		model interface{}
+		index string
+	}{
+		{&EventType{}, "idx_event_types_slug"},
+		{&Interviewer{}, "idx_interviewers_email"},
+		{&Holiday{}, "idx_holiday_calendar_date"},
+		{&AvailabilityOverride{}, "idx_override_interviewer_date"},
+	}
+	for _, l := range legacy {
+		if db.Migrator().HasIndex(l.model, l.index) {
+			if err := db.Migrator().DropIndex(l.model, l.index); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Window is an interval an interviewer offers, e.g. a TimeSlot or an availability window.
+type Window struct {
+	ID             uint
+	OrganizationID uint
+	InterviewerID  uint
+	StartTime      time.Time
+	EndTime        time.Time
+}
+
+// OverlapConflict is a pair of overlapping windows of the same interviewer.
+type OverlapConflict struct {
+	Kind           string    `json:"kind"` // SlotKindTimeSlot or SlotKindAvailability
+	OrganizationID uint      `json:"organization_id"`
+	InterviewerID  uint      `json:"interviewer_id"`
+	FirstID        uint      `json:"first_id"`
+	FirstStart     time.Time `json:"first_start"`
+	FirstEnd       time.Time `json:"first_end"`
+	SecondID       uint      `json:"second_id"`
+	SecondStart    time.Time `json:"second_start"`
+	SecondEnd      time.Time `json:"second_end"`
+}
+
+// OverlapTable describes a table of interviewer windows that an exclusion
+// constraint can guard.
+type OverlapTable struct {
+	Name        string
+	RangeType   string // tstzrange for timestamptz columns, tsrange for timestamp
+	SoftDeleted bool   // only rows without deleted_at take part
+}
+
+// TimeSlotOverlapTable is the time_slots table as an OverlapTable.
+var TimeSlotOverlapTable = OverlapTable{Name: "time_slots", RangeType: "tstzrange"}
+
+// -------------------- Overlap Checks --------------------
+
+// OverlappingWindows scopes a query on model to the windows of the interviewer
+// of the organization that overlap [start, end), leaving out excludeIDs; the
+// organization is explicit as interviewer IDs may coincide across organizations
+// in unscoped sessions. With adjacent set, windows that only touch the interval
+// match too, which is what merging needs.
+func OverlappingWindows(db *gorm.DB, model interface{}, organizationID, interviewerID uint, start, end time.Time, excludeIDs []uint, adjacent bool) *gorm.DB {
+	query := db.Model(model).Where("organization_id = ? AND interviewer_id = ?", organizationID, interviewerID)
+	if adjacent {
+		query = query.Where("start_time <= ? AND end_time >= ?", end, start)
+	} else {
+		query = query.Where("start_time < ? AND end_time > ?", end, start)
+	}
+	if len(excludeIDs) > 0 {
+		query = query.Where("id NOT IN ?", excludeIDs)
+	}
+	return query.Order("start_time, id")
+}
+
+// CheckTimeSlotOverlap rejects an interval that overlaps another time slot of
+// the interviewer of the organization. Touching slots do not overlap.
+func CheckTimeSlotOverlap(db *gorm.DB, organizationID, interviewerID uint, start, end time.Time, excludeID uint) error {
+	var slots []TimeSlot
+	if err := OverlappingWindows(db, &TimeSlot{}, organizationID, interviewerID, start, end, []uint{excludeID}, false).Limit(1).Find(&slots).Error; err != nil {
+		return errors.New("failed to check overlapping time slots")
+	}
+	if len(slots) > 0 {
+		return Conflict("slot_overlap", fmt.Sprintf("overlaps time slot %d of this interviewer", slots[0].ID))
+	}
+	return nil
+}
+
+// mergeTimeSlots widens slot to cover the open slots it overlaps or touches,
+// repeatedly as it grows, and deletes them. A booked slot in the way is a
+// conflict; one that only touches is left alone.
+func mergeTimeSlots(tx *gorm.DB, slot *TimeSlot) error {
+	seen := []uint{slot.ID}
+	var merged []uint
+	for {
+		var neighbours []TimeSlot
+		if err := OverlappingWindows(tx, &TimeSlot{}, slot.OrganizationID, slot.InterviewerID, slot.StartTime, slot.EndTime, seen, true).Find(&neighbours).Error; err != nil {
+			return errors.New("failed to fetch time slots to merge")
+		}
+		if len(neighbours) == 0 {
+			break
+		}
+
+		for _, other := range neighbours {
+			seen = append(seen, other.ID)
+			if other.IsBooked {
+				if other.StartTime.Before(slot.EndTime) && other.EndTime.After(slot.StartTime) {
+					return Conflict("slot_overlap", fmt.Sprintf("overlaps booked time slot %d of this interviewer", other.ID))
+				}
+				continue
+			}
+			if other.StartTime.Before(slot.StartTime) {
+				slot.StartTime = other.StartTime
+			}
+			if other.EndTime.After(slot.EndTime) {
+				slot.EndTime = other.EndTime
+			}
+			merged = append(merged, other.ID)
+		}
+	}
+
+	if len(merged) > 0 {
+		if err := tx.Delete(&TimeSlot{}, merged).Error; err != nil {
+			return errors.New("failed to delete merged time slots")
+		}
+	}
+	return nil
+}
+
+// OverlapConstraintError turns a violated exclusion constraint into a conflict
+// and returns other errors unchanged.
+func OverlapConstraintError(err error) error {
+	// exclusion_violation; the driver's error is not translated by gorm
+	if err != nil && strings.Contains(err.Error(), "SQLSTATE 23P01") {
+		return Conflict("slot_overlap", "overlaps another window of this interviewer")
+	}
+	return err
+}
+
+// -------------------- Exclusion Constraints --------------------
+
+// EnableOverlapConstraint makes PostgreSQL reject overlapping windows of one
+// interviewer of an organization in table. Other drivers rely on the checks
+// made before every create and update. A constraint from before organizations
+// is replaced. It fails while the table still holds overlaps; list them with
+// FindOverlapConflicts.
+func EnableOverlapConstraint(db *gorm.DB, table OverlapTable) error {
+	if db.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	name := table.Name + "_no_overlap"
+	var definitions []string
+	if err := db.Raw("SELECT pg_get_constraintdef(oid) FROM pg_constraint WHERE conname = ?", name).Scan(&definitions).Error; err != nil {
+		return err
+	}
+	drop := ""
+	if len(definitions) > 0 {
+		if strings.Contains(definitions[0], "organization_id") {
+			return nil
+		}
+		// Interviewer IDs are not unique across organizations
+		drop = fmt.Sprintf(" DROP CONSTRAINT %s,", name)
+	}
+
+	// btree_gist lets the constraint compare organization_id and interviewer_id with = next to the range
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS btree_gist").Error; err != nil {
+		return err
+	}
+	where := ""
+	if table.SoftDeleted {
+		where = " WHERE (deleted_at IS NULL)"
+	}
+	sql := fmt.Sprintf(`ALTER TABLE %s%s ADD CONSTRAINT %s EXCLUDE USING gist (organization_id WITH =, interviewer_id WITH =, %s(start_time, end_time) WITH &&)%s`,
+		table.Name, drop, name, table.RangeType, where)
+	if err := db.Exec(sql).Error; err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	return nil
+}
+
+// -------------------- Conflict Report --------------------
+
+// FindOverlapConflicts lists every pair of overlapping windows of the same
+// interviewer of an organization, ordered by organization, interviewer and
+// start time.
+func FindOverlapConflicts(kind string, windows []Window) []OverlapConflict {
+	sort.Slice(windows, func(i, j int) bool {
+		if windows[i].OrganizationID != windows[j].OrganizationID {
+			return windows[i].OrganizationID < windows[j].OrganizationID
+		}
+		if windows[i].InterviewerID != windows[j].InterviewerID {
+			return windows[i].InterviewerID < windows[j].InterviewerID
+		}
+		if !windows[i].StartTime.Equal(windows[j].StartTime) {
+			return windows[i].StartTime.Before(windows[j].StartTime)
+		}
+		return windows[i].ID < windows[j].ID
+	})
+
+	conflicts := []OverlapConflict{}
+	var active []Window // windows of the current organization's interviewer still open at the current start
+	for _, window := range windows {
+		open := active[:0]
+		for _, other := range active {
+			if other.OrganizationID == window.OrganizationID && other.InterviewerID == window.InterviewerID && other.EndTime.After(window.StartTime) {
+				open = append(open, other)
+			}
+		}
+		active = open
+
+		for _, other := range active {
+			if window.EndTime.After(other.StartTime) {
+				conflicts = append(conflicts, OverlapConflict{
+					Kind:           kind,
+					OrganizationID: window.OrganizationID,
+					InterviewerID:  window.InterviewerID,
+					FirstID:        other.ID,
+					FirstStart:     other.StartTime,
+					FirstEnd:       other.EndTime,
+					SecondID:       window.ID,
+					SecondStart:    window.StartTime,
+					SecondEnd:      window.EndTime,
+				})
+			}
+		}
+		active = append(active, window)
+	}
+	return conflicts
+}
+
+// TimeSlotConflicts reports overlapping time slots, of one interviewer or, for 0, of all.
+func TimeSlotConflicts(db *gorm.DB, interviewerID uint) ([]OverlapConflict, error) {
+	query := db.Model(&TimeSlot{})
+	if interviewerID != 0 {
+		query = query.Where("interviewer_id = ?", interviewerID)
+	}
+	var slots []TimeSlot
+	if err := query.Find(&slots).Error; err != nil {
+		return nil, errors.New("failed to fetch time slots")
+	}
+
+	windows := make([]Window, 0, len(slots))
+	for _, slot := range slots {
+		windows = append(windows, Window{ID: slot.ID, OrganizationID: slot.OrganizationID, InterviewerID: slot.InterviewerID, StartTime: slot.StartTime, EndTime: slot.EndTime})
+	}
+	return FindOverlapConflicts(SlotKindTimeSlot, windows), nil
+}
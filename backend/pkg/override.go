@@ -0,0 +1,147 @@
+package pkg
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// -------------------- Models --------------------
+
+// AvailabilityOverride replaces or blocks an interviewer's normal hours on a single date.
+type AvailabilityOverride struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"uniqueIndex:idx_override_org_interviewer_date"`
+	InterviewerID  uint      `json:"interviewer_id" gorm:"uniqueIndex:idx_override_org_interviewer_date"`
+	Date           string    `json:"date" gorm:"uniqueIndex:idx_override_org_interviewer_date"` // YYYY-MM-DD
+	Unavailable    bool      `json:"unavailable"`                                               // true blocks the whole date
+	StartTime      time.Time `json:"start_time,omitempty"`                                      // replacement hours when available
+	EndTime        time.Time `json:"end_time,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// DateRule describes how a single date deviates from an interviewer's normal hours.
+type DateRule struct {
+	Blocked  bool                  // no availability at all on this date
+	Reason   string                // holiday name or override reason when blocked
+	Override *AvailabilityOverride // replacement hours, nil when normal hours apply
+}
+
+// -------------------- Override Functions --------------------
+
+// SetAvailabilityOverride creates or replaces the override for an interviewer on a date.
+func SetAvailabilityOverride(db *gorm.DB, interviewerID uint, date string, unavailable bool, startTime, endTime time.Time, reason string) (*AvailabilityOverride, error) {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, Validation("invalid_date", "invalid date format; expected YYYY-MM-DD")
+	}
+
+	// Replacement hours must form a valid window
+	if !unavailable {
+		if startTime.IsZero() || endTime.IsZero() {
+			return nil, Validation("invalid_time_range", "start time and end time are required unless the date is unavailable")
+		}
+		if !startTime.Before(endTime) {
+			return nil, Validation("invalid_time_range", "start time must be before end time")
+		}
+		if startTime.Format("2006-01-02") != date || endTime.Format("2006-01-02") != date {
+			return nil, Validation("time_outside_date", "start time and end time must fall on the override's date")
+		}
+	} else {
+		startTime, endTime = time.Time{}, time.Time{}
+	}
+
+	override := AvailabilityOverride{
+		InterviewerID: interviewerID,
+		Date:          date,
+		Unavailable:   unavailable,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		Reason:        reason,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	// One override per interviewer and date in an organization; a second call replaces the first
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "interviewer_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"unavailable", "start_time", "end_time", "reason", "updated_at"}),
+	}).Create(&override).Error
+	if err != nil {
+		return nil, errors.New("failed to save availability override")
+	}
+
+	return &override, nil
+}
+
+// ListAvailabilityOverrides returns an interviewer's overrides, optionally limited to a date range.
+func ListAvailabilityOverrides(db *gorm.DB, interviewerID uint, from, to string) ([]AvailabilityOverride, error) {
+	query := db.Where("interviewer_id = ?", interviewerID)
+	if from != "" {
+		query = query.Where("date >= ?", from)
+	}
+	if to != "" {
+		query = query.Where("date <= ?", to)
+	}
+
+	var overrides []AvailabilityOverride
+	if err := query.Order("date").Find(&overrides).Error; err != nil {
+		return nil, errors.New("failed to fetch availability overrides")
+	}
+	return overrides, nil
+}
+
+// DeleteAvailabilityOverride removes an override so normal hours apply again.
+func DeleteAvailabilityOverride(db *gorm.DB, id uint) error {
+	result := db.Delete(&AvailabilityOverride{}, id)
+	if result.Error != nil {
+		return errors.New("failed to delete availability override")
+	}
+	if result.RowsAffected == 0 {
+		return NotFound("override_not_found", "availability override not found")
+	}
+	return nil
+}
+
+// ResolveDateRule combines holidays and the interviewer's override for a date.
+// Holidays win over overrides because they apply to the whole organization.
+func ResolveDateRule(db *gorm.DB, interviewerID uint, date string) (*DateRule, error) {
+	holiday, err := FindHoliday(db, date)
+	if err != nil {
+		return nil, err
+	}
+	if holiday != nil {
+		return &DateRule{Blocked: true, Reason: holiday.Name}, nil
+	}
+
+	var overrides []AvailabilityOverride
+	if err := db.Where("interviewer_id = ? AND date = ?", interviewerID, date).Limit(1).Find(&overrides).Error; err != nil {
+		return nil, errors.New("failed to fetch availability override")
+	}
+	if len(overrides) == 0 {
+		return &DateRule{}, nil
+	}
+
+	override := overrides[0]
+	if override.Unavailable {
+		return &DateRule{Blocked: true, Reason: override.Reason}, nil
+	}
+	return &DateRule{Override: &override}, nil
+}
+
+// OverridesForDate returns every interviewer's override on a date, keyed by interviewer ID.
+func OverridesForDate(db *gorm.DB, date string) (map[uint]AvailabilityOverride, error) {
+	var overrides []AvailabilityOverride
+	if err := db.Where("date = ?", date).Find(&overrides).Error; err != nil {
+		return nil, errors.New("failed to fetch availability overrides")
+	}
+
+	byInterviewer := make(map[uint]AvailabilityOverride, len(overrides))
+	for _, override := range overrides {
+		byInterviewer[override.InterviewerID] = override
+	}
+	return byInterviewer, nil
+}
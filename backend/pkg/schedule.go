@@ -0,0 +1,521 @@
+package pkg
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultStepMinutes is the slot length used when an interviewer has no template for a date.
+const DefaultStepMinutes = 60
+
+// -------------------- Models --------------------
+
+// ScheduleTemplate defines an interviewer's recurring hours for one weekday.
+// Start and end are wall-clock times in the interviewer's time zone.
+type ScheduleTemplate struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"index"`
+	InterviewerID  uint      `json:"interviewer_id" gorm:"index"`
+	Weekday        int       `json:"weekday"`    // 0 = Sunday ... 6 = Saturday
+	StartTime      string    `json:"start_time"` // HH:MM
+	EndTime        string    `json:"end_time"`   // HH:MM
+	StepMinutes    int       `json:"step_minutes"`
+	TimeZone       string    `json:"time_zone"` // IANA name, e.g. America/New_York
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// AvailableSlot is a concrete bookable interval generated from a schedule template.
+type AvailableSlot struct {
+	InterviewerID uint      `json:"interviewer_id"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	Label         string    `json:"label"` // start time for display, e.g. "9:00 AM"
+}
+
+// SlotRangeQuery selects the slots returned by GenerateSlotRange.
+type SlotRangeQuery struct {
+	From            string         // first day, YYYY-MM-DD, inclusive
+	To              string         // last day, YYYY-MM-DD, inclusive
+	InterviewerIDs  []uint         // empty means every interviewer
+	DurationMinutes int            // slot length from the event type; 0 uses each template's step
+	Location        *time.Location // time zone used to group slots by day; nil means UTC
+	CountOnly       bool           // count each day's slots without listing them
+}
+
+// DaySlots groups the open slots of one calendar day.
+type DaySlots struct {
+	Date  string          `json:"date"`
+	Count int             `json:"count"`
+	Slots []AvailableSlot `json:"slots,omitempty"`
+}
+
+// -------------------- Template Functions --------------------
+
+// SaveScheduleTemplate validates and stores a weekday template for an interviewer.
+func SaveScheduleTemplate(db *gorm.DB, interviewerID uint, weekday int, startTime, endTime string, stepMinutes int, timeZone string) (*ScheduleTemplate, error) {
+	if weekday < 0 || weekday > 6 {
+		return nil, Validation("invalid_weekday", "weekday must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	if stepMinutes <= 0 {
+		return nil, Validation("invalid_step", "step minutes must be positive")
+	}
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return nil, Validation("invalid_time_zone", "invalid time zone")
+	}
+
+	start, err := time.Parse("15:04", startTime)
+	if err != nil {
+		return nil, Validation("invalid_time", "invalid start time; expected HH:MM")
+	}
+	end, err := time.Parse("15:04", endTime)
+	if err != nil {
+		return nil, Validation("invalid_time", "invalid end time; expected HH:MM")
+	}
+	if !start.Before(end) {
+		return nil, Validation("invalid_time_range", "start time must be before end time")
+	}
+	if end.Sub(start) < time.Duration(stepMinutes)*time.Minute {
+		return nil, Validation("invalid_step", "step is longer than the scheduled window")
+	}
+
+	template := ScheduleTemplate{
+		InterviewerID: interviewerID,
+		Weekday:       weekday,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		StepMinutes:   stepMinutes,
+		TimeZone:      timeZone,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if err := db.Create(&template).Error; err != nil {
+		return nil, errors.New("failed to save schedule template")
+	}
+	return &template, nil
+}
+
+// ListScheduleTemplates returns an interviewer's templates ordered by weekday and start.
+func ListScheduleTemplates(db *gorm.DB, interviewerID uint) ([]ScheduleTemplate, error) {
+	var templates []ScheduleTemplate
+	if err := db.Where("interviewer_id = ?", interviewerID).Order("weekday, start_time").Find(&templates).Error; err != nil {
+		return nil, errors.New("failed to fetch schedule templates")
+	}
+	return templates, nil
+}
+
+// DeleteScheduleTemplate removes a template.
+func DeleteScheduleTemplate(db *gorm.DB, id uint) error {
+	result := db.Delete(&ScheduleTemplate{}, id)
+	if result.Error != nil {
+		return errors.New("failed to delete schedule template")
+	}
+	if result.RowsAffected == 0 {
+		return NotFound("schedule_template_not_found", "schedule template not found")
+	}
+	return nil
+}
+
+// -------------------- Slot Generation --------------------
+
+// GenerateAvailableSlots expands schedule templates into open slots for a date.
+// The date is interpreted in each interviewer's own time zone. Holidays, date
+// overrides and existing bookings are applied. An interviewerID of 0 covers
+// every interviewer with a template or override.
+func GenerateAvailableSlots(db *gorm.DB, interviewerID uint, date string) ([]AvailableSlot, error) {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, Validation("invalid_date", "invalid date format; expected YYYY-MM-DD")
+	}
+
+	var interviewerIDs []uint
+	if interviewerID != 0 {
+		interviewerIDs = []uint{interviewerID}
+	}
+	return generateSlots(db, interviewerIDs, date, 0)
+}
+
+// GenerateSlotRange returns open slots for every day from query.From to query.To,
+// grouped by calendar day in query.Location. Days without openings are included
+// with a zero count. Everything the slots depend on is loaded once for the range.
+func GenerateSlotRange(db *gorm.DB, query SlotRangeQuery) ([]DaySlots, error) {
+	from, err := time.Parse("2006-01-02", query.From)
+	if err != nil {
+		return nil, Validation("invalid_date", "invalid from date; expected YYYY-MM-DD")
+	}
+	to, err := time.Parse("2006-01-02", query.To)
+	if err != nil {
+		return nil, Validation("invalid_date", "invalid to date; expected YYYY-MM-DD")
+	}
+	if to.Before(from) {
+		return nil, Validation("invalid_date_range", "to date must not be before from date")
+	}
+	location := query.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	days := []DaySlots{}
+	index := map[string]int{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		index[day.Format("2006-01-02")] = len(days)
+		days = append(days, DaySlots{Date: day.Format("2006-01-02")})
+		if !query.CountOnly {
+			days[len(days)-1].Slots = []AvailableSlot{}
+		}
+	}
+
+	// Interviewer-local dates can spill into neighbouring days of the requested zone
+	first, last := from.AddDate(0, 0, -1), to.AddDate(0, 0, 1)
+	source, err := loadSlotSource(db, query.InterviewerIDs, first, last)
+	if err != nil {
+		return nil, err
+	}
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		for _, interviewerID := range source.interviewerIDs {
+			source.eachOpenSlot(interviewerID, day.Format("2006-01-02"), query.DurationMinutes, func(start, end time.Time) {
+				start, end = start.In(location), end.In(location)
+				i, ok := index[start.Format("2006-01-02")]
+				if !ok {
+					return
+				}
+				days[i].Count++
+				if !query.CountOnly {
+					days[i].Slots = append(days[i].Slots, AvailableSlot{InterviewerID: interviewerID, StartTime: start, EndTime: end, Label: start.Format("3:04 PM")})
+				}
+			})
+		}
+	}
+
+	for i := range days {
+		sortSlots(days[i].Slots)
+	}
+	return days, nil
+}
+
+// generateSlots builds open slots for a date across the given interviewers, or all when empty.
+// A positive durationMinutes replaces each template's step.
+func generateSlots(db *gorm.DB, interviewerIDs []uint, date string, durationMinutes int) ([]AvailableSlot, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, Validation("invalid_date", "invalid date format; expected YYYY-MM-DD")
+	}
+	source, err := loadSlotSource(db, interviewerIDs, day, day)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := []AvailableSlot{}
+	for _, interviewerID := range source.interviewerIDs {
+		source.eachOpenSlot(interviewerID, date, durationMinutes, func(start, end time.Time) {
+			slots = append(slots, AvailableSlot{InterviewerID: interviewerID, StartTime: start, EndTime: end, Label: start.Format("3:04 PM")})
+		})
+	}
+
+	sortSlots(slots)
+	return slots, nil
+}
+
+// sortSlots orders slots by start time, then interviewer.
+func sortSlots(slots []AvailableSlot) {
+	sort.Slice(slots, func(i, j int) bool {
+		if !slots[i].StartTime.Equal(slots[j].StartTime) {
+			return slots[i].StartTime.Before(slots[j].StartTime)
+		}
+		return slots[i].InterviewerID < slots[j].InterviewerID
+	})
+}
+
+// slotSource is what slot generation reads for a span of dates: templates,
+// overrides, holidays and the intervals taken by bookings and waitlist holds.
+type slotSource struct {
+	interviewerIDs []uint // with a template or an override in the span
+	templates      map[uint][]ScheduleTemplate
+	overrides      map[uint]map[string]AvailabilityOverride // by interviewer, then date
+	holidays       map[string]bool
+	taken          map[uint][]Booking // only StartTime and EndTime are used
+	now            time.Time
+}
+
+// loadSlotSource loads the slot sources of the interviewers, or of all when
+// empty, for the dates from first to last, in a fixed number of queries.
+func loadSlotSource(db *gorm.DB, interviewerIDs []uint, first, last time.Time) (*slotSource, error) {
+	source := &slotSource{
+		templates: map[uint][]ScheduleTemplate{},
+		overrides: map[uint]map[string]AvailabilityOverride{},
+		holidays:  map[string]bool{},
+		taken:     map[uint][]Booking{},
+		now:       time.Now(),
+	}
+	forInterviewers := func(query *gorm.DB) *gorm.DB {
+		if len(interviewerIDs) > 0 {
+			return query.Where("interviewer_id IN ?", interviewerIDs)
+		}
+		return query
+	}
+	firstDate, lastDate := first.Format("2006-01-02"), last.Format("2006-01-02")
+
+	var templates []ScheduleTemplate
+	if err := forInterviewers(db.Model(&ScheduleTemplate{})).Order("interviewer_id, start_time").Find(&templates).Error; err != nil {
+		return nil, errors.New("failed to fetch schedule templates")
+	}
+	for _, template := range templates {
+		source.templates[template.InterviewerID] = append(source.templates[template.InterviewerID], template)
+	}
+
+	var overrides []AvailabilityOverride
+	if err := forInterviewers(db.Where("date >= ? AND date <= ?", firstDate, lastDate)).Find(&overrides).Error; err != nil {
+		return nil, errors.New("failed to fetch availability overrides")
+	}
+	for _, override := range overrides {
+		if source.overrides[override.InterviewerID] == nil {
+			source.overrides[override.InterviewerID] = map[string]AvailabilityOverride{}
+		}
+		source.overrides[override.InterviewerID][override.Date] = override
+	}
+
+	holidays, err := ListHolidays(db, firstDate, lastDate)
+	if err != nil {
+		return nil, err
+	}
+	for _, holiday := range holidays {
+		source.holidays[holiday.Date] = true
+	}
+
+	// Interviewer-local days reach up to a day beyond the UTC dates
+	windowStart, windowEnd := first.AddDate(0, 0, -1), last.AddDate(0, 0, 2)
+	var bookings []Booking
+	err = forInterviewers(db.Where("start_time < ? AND end_time > ? AND status <> ?", windowEnd, windowStart, "cancelled")).
+		Select("interviewer_id, start_time, end_time").Find(&bookings).Error
+	if err != nil {
+		return nil, errors.New("failed to fetch bookings")
+	}
+	for _, booking := range bookings {
+		source.taken[booking.InterviewerID] = append(source.taken[booking.InterviewerID], booking)
+	}
+
+	// Slots reserved for a waitlisted candidate stay hidden until claimed or expired
+	holds, err := ActiveHolds(db, interviewerIDs, windowStart, windowEnd)
+	if err != nil {
+		return nil, err
+	}
+	for _, hold := range holds {
+		source.taken[hold.InterviewerID] = append(source.taken[hold.InterviewerID], Booking{StartTime: hold.StartTime, EndTime: hold.EndTime})
+	}
+
+	for id := range source.templates {
+		source.interviewerIDs = append(source.interviewerIDs, id)
+	}
+	for id := range source.overrides {
+		if _, ok := source.templates[id]; !ok {
+			source.interviewerIDs = append(source.interviewerIDs, id)
+		}
+	}
+	sort.Slice(source.interviewerIDs, func(i, j int) bool { return source.interviewerIDs[i] < source.interviewerIDs[j] })
+	return source, nil
+}
+
+// location is the time zone of the interviewer's templates, UTC without any.
+func (s *slotSource) location(interviewerID uint) *time.Location {
+	if templates := s.templates[interviewerID]; len(templates) > 0 {
+		if location, err := time.LoadLocation(templates[0].TimeZone); err == nil {
+			return location
+		}
+	}
+	return time.UTC
+}
+
+// eachOpenSlot calls yield with every open slot of the interviewer on a date,
+// in the interviewer's time zone. Holidays and blackouts leave the date empty,
+// replacement hours take the place of every template, and slots that started
+// or overlap a booking or hold are skipped.
+func (s *slotSource) eachOpenSlot(interviewerID uint, date string, durationMinutes int, yield func(start, end time.Time)) {
+	if s.holidays[date] {
+		return
+	}
+	override, hasOverride := s.overrides[interviewerID][date]
+	if hasOverride && override.Unavailable {
+		return
+	}
+
+	location := s.location(interviewerID)
+	templates := s.templates[interviewerID]
+	stepMinutes := DefaultStepMinutes
+	if len(templates) > 0 {
+		stepMinutes = templates[0].StepMinutes
+	}
+	if durationMinutes > 0 {
+		stepMinutes = durationMinutes
+	}
+
+	taken := s.taken[interviewerID]
+	emit := func(start, end time.Time, step time.Duration) {
+		if step <= 0 {
+			return
+		}
+		for slotStart := start; !slotStart.Add(step).After(end); slotStart = slotStart.Add(step) {
+			slotEnd := slotStart.Add(step)
+			if slotStart.After(s.now) && !overlapsAny(slotStart, slotEnd, taken) {
+				yield(slotStart, slotEnd)
+			}
+		}
+	}
+
+	if hasOverride {
+		emit(override.StartTime.In(location), override.EndTime.In(location), time.Duration(stepMinutes)*time.Minute)
+		return
+	}
+	day, _ := time.ParseInLocation("2006-01-02", date, location)
+	for _, template := range templates {
+		if template.Weekday != int(day.Weekday()) {
+			continue
+		}
+		start, errStart := wallClock(day, template.StartTime)
+		end, errEnd := wallClock(day, template.EndTime)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+		step := template.StepMinutes
+		if durationMinutes > 0 {
+			step = durationMinutes
+		}
+		emit(start, end, time.Duration(step)*time.Minute)
+	}
+}
+
+// IsSlotOffered reports whether an interval exactly matches an open slot generated for the interviewer.
+func IsSlotOffered(db *gorm.DB, interviewerID uint, startTime, endTime time.Time) (bool, error) {
+	day := startTime.UTC().Truncate(24 * time.Hour)
+	source, err := loadSlotSource(db, []uint{interviewerID}, day.AddDate(0, 0, -1), day.AddDate(0, 0, 1))
+	if err != nil {
+		return false, err
+	}
+
+	offered := false
+	date := startTime.In(source.location(interviewerID)).Format("2006-01-02")
+	source.eachOpenSlot(interviewerID, date, 0, func(start, end time.Time) {
+		if start.Equal(startTime) && end.Equal(endTime) {
+			offered = true
+		}
+	})
+	return offered, nil
+}
+
+// ScheduleSlotAt resolves a wall clock (15:04) on a date in the interviewer's
+// time zone to the schedule interval starting then; the end follows the step
+// of the schedule or replacement hours. Legacy clients send only the start.
+func ScheduleSlotAt(db *gorm.DB, interviewerID uint, date, clock string) (time.Time, time.Time, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}, time.Time{}, Validation("invalid_date", "invalid date format, use YYYY-MM-DD")
+	}
+	source, err := loadSlotSource(db, []uint{interviewerID}, day.AddDate(0, 0, -1), day.AddDate(0, 0, 1))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	local, _ := time.ParseInLocation("2006-01-02", date, source.location(interviewerID))
+	start, err := wallClock(local, clock)
+	if err != nil {
+		return time.Time{}, time.Time{}, Validation("invalid_time", "invalid time format")
+	}
+
+	end := time.Time{}
+	source.eachOpenSlot(interviewerID, date, 0, func(slotStart, slotEnd time.Time) {
+		if end.IsZero() && slotStart.Equal(start) {
+			end = slotEnd
+		}
+	})
+	if end.IsZero() {
+		// Not open; BookScheduleSlot tells taken and unavailable apart
+		step := DefaultStepMinutes
+		if templates := source.templates[interviewerID]; len(templates) > 0 {
+			step = templates[0].StepMinutes
+		}
+		end = start.Add(time.Duration(step) * time.Minute)
+	}
+	return start.UTC(), end.UTC(), nil
+}
+
+// BookScheduleSlot saves a booking of an interval of the interviewer's weekly
+// schedule through PlaceBooking. The interval must still be offered: the
+// interviewer is locked for the transaction, so concurrent requests for the
+// same interval cannot both find it free.
+func BookScheduleSlot(db *gorm.DB, booking *Booking) error {
+	if !booking.StartTime.After(time.Now()) {
+		return PastSlot("slot_in_past", "cannot book a past time slot")
+	}
+	booking.SlotKind = SlotKindSchedule
+
+	return PlaceBooking(db, booking, func(tx *gorm.DB) error {
+		offered, err := IsSlotOffered(tx, booking.InterviewerID, booking.StartTime, booking.EndTime)
+		if err != nil {
+			return err
+		}
+		if offered {
+			return nil
+		}
+		// Either outside the schedule or already taken; tell the two apart
+		overlapping, err := HasOverlappingBooking(tx, booking.InterviewerID, booking.StartTime, booking.EndTime)
+		if err != nil {
+			return err
+		}
+		if overlapping {
+			return Conflict("slot_already_booked", "slot already booked")
+		}
+		return Validation("slot_unavailable", "requested time is not an available slot")
+	})
+}
+
+// lockInterviewer makes concurrent transactions booking the interviewer's time
+// wait for each other until tx ends. PostgreSQL takes an advisory lock, which
+// needs no interviewers row; other databases, e.g. SQLite, already serialize
+// write transactions.
+func lockInterviewer(tx *gorm.DB, interviewerID uint) error {
+	if tx.Dialector.Name() != "postgres" {
+		return nil
+	}
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?, ?)", interviewerLockClass, int32(interviewerID)).Error; err != nil {
+		return errors.New("failed to lock interviewer")
+	}
+	return nil
+}
+
+// interviewerLockClass is the first key of interviewer advisory locks
+const interviewerLockClass = 1
+
+// HasOverlappingBooking reports whether an active booking overlaps the interval.
+func HasOverlappingBooking(db *gorm.DB, interviewerID uint, startTime, endTime time.Time) (bool, error) {
+	var count int64
+	err := db.Model(&Booking{}).
+		Where("interviewer_id = ? AND start_time < ? AND end_time > ? AND status <> ?", interviewerID, endTime.UTC(), startTime.UTC(), "cancelled").
+		Count(&count).Error
+	if err != nil {
+		return false, errors.New("failed to check existing bookings")
+	}
+	return count > 0, nil
+}
+
+// wallClock combines a local date with an HH:MM wall-clock time in the date's location.
+func wallClock(day time.Time, clock string) (time.Time, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), parsed.Hour(), parsed.Minute(), 0, 0, day.Location()), nil
+}
+
+// overlapsAny reports whether [start, end) intersects any booking interval.
+func overlapsAny(start, end time.Time, bookings []Booking) bool {
+	for _, booking := range bookings {
+		if start.Before(booking.EndTime) && end.After(booking.StartTime) {
+			return true
+		}
+	}
+	return false
+}
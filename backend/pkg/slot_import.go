@@ -0,0 +1,264 @@
+package pkg
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxSlotImportRows caps one import so a single request stays a single transaction.
+const MaxSlotImportRows = 5000
+
+// Slot import row statuses
+const (
+	SlotImportValid   = "valid"   // passed validation; dry runs stop here
+	SlotImportCreated = "created" // stored as a TimeSlot
+	SlotImportInvalid = "invalid"
+)
+
+// SlotImportRow is one interviewer/start/end row of an import and its outcome.
+type SlotImportRow struct {
+	Row           int       `json:"row"` // CSV line number, or 1-based position in a JSON array
+	InterviewerID uint      `json:"interviewer_id"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	Status        string    `json:"status"`
+	Code          string    `json:"code,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	SlotID        uint      `json:"slot_id,omitempty"`
+}
+
+// SlotImportReport is the per-row result of an import. Nothing is stored unless
+// Committed is true, which needs commit mode and every row valid.
+type SlotImportReport struct {
+	Committed bool            `json:"committed"`
+	Total     int             `json:"total"`
+	Valid     int             `json:"valid"`
+	Invalid   int             `json:"invalid"`
+	Created   int             `json:"created"`
+	Rows      []SlotImportRow `json:"rows"`
+}
+
+// -------------------- Parsers --------------------
+
+// ParseSlotImportCSV reads rows under an "interviewer_id,start_time,end_time"
+// header, in any column order. Times are RFC 3339. Rows with malformed values are
+// returned as invalid rather than failing the whole file.
+func ParseSlotImportCSV(r io.Reader) ([]SlotImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, Validation("invalid_slot_file", "CSV must start with an interviewer_id,start_time,end_time header")
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"interviewer_id", "start_time", "end_time"} {
+		if _, ok := columns[name]; !ok {
+			return nil, Validation("invalid_slot_file", fmt.Sprintf("CSV header is missing the %s column", name))
+		}
+	}
+
+	var rows []SlotImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, Validation("invalid_slot_file", fmt.Sprintf("invalid CSV: %v", err))
+		}
+		if len(rows) == MaxSlotImportRows {
+			return nil, Validation("too_many_rows", fmt.Sprintf("an import can have at most %d rows", MaxSlotImportRows))
+		}
+
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, parseSlotImportRow(line, field("interviewer_id"), field("start_time"), field("end_time")))
+	}
+	return rows, nil
+}
+
+// ParseSlotImportJSON reads an array of {"interviewer_id", "start_time", "end_time"} objects.
+func ParseSlotImportJSON(r io.Reader) ([]SlotImportRow, error) {
+	var records []struct {
+		InterviewerID json.Number `json:"interviewer_id"`
+		StartTime     string      `json:"start_time"`
+		EndTime       string      `json:"end_time"`
+	}
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	if err := decoder.Decode(&records); err != nil {
+		return nil, Validation("invalid_slot_file", "JSON must be an array of {interviewer_id, start_time, end_time} objects")
+	}
+	if len(records) > MaxSlotImportRows {
+		return nil, Validation("too_many_rows", fmt.Sprintf("an import can have at most %d rows", MaxSlotImportRows))
+	}
+
+	rows := make([]SlotImportRow, 0, len(records))
+	for i, record := range records {
+		rows = append(rows, parseSlotImportRow(i+1, record.InterviewerID.String(), record.StartTime, record.EndTime))
+	}
+	return rows, nil
+}
+
+// parseSlotImportRow converts one row's text fields, marking it invalid if any is malformed
+func parseSlotImportRow(row int, interviewerID, startTime, endTime string) SlotImportRow {
+	result := SlotImportRow{Row: row}
+	invalid := func(message string) SlotImportRow {
+		result.Status, result.Code, result.Error = SlotImportInvalid, "invalid_row", message
+		return result
+	}
+
+	id, err := strconv.ParseUint(interviewerID, 10, 0)
+	if err != nil {
+		return invalid(fmt.Sprintf("invalid interviewer_id %q", interviewerID))
+	}
+	result.InterviewerID = uint(id)
+	if result.StartTime, err = time.Parse(time.RFC3339, startTime); err != nil {
+		return invalid(fmt.Sprintf("invalid start_time %q; expected RFC 3339", startTime))
+	}
+	if result.EndTime, err = time.Parse(time.RFC3339, endTime); err != nil {
+		return invalid(fmt.Sprintf("invalid end_time %q; expected RFC 3339", endTime))
+	}
+	result.StartTime, result.EndTime = result.StartTime.UTC(), result.EndTime.UTC()
+	return result
+}
+
+// -------------------- Import --------------------
+
+// ImportTimeSlots validates every row with the CreateTimeSlot rules and checks it
+// against the interviewer's existing slots and the rows before it. In commit mode,
+// and only if every row is valid, all slots are created in one transaction.
+func ImportTimeSlots(db *gorm.DB, rows []SlotImportRow, commit bool) (*SlotImportReport, error) {
+	report := &SlotImportReport{Total: len(rows), Rows: rows}
+	accepted := map[uint][]SlotImportRow{} // valid rows so far, per interviewer
+
+	existing, err := existingSlotsFor(db, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if row.Status == SlotImportInvalid {
+			report.Invalid++
+			continue
+		}
+
+		err := ValidateTimeSlot(row.InterviewerID, row.StartTime, row.EndTime)
+		if err == nil {
+			err = checkImportOverlap(existing[row.InterviewerID], accepted[row.InterviewerID], *row)
+		}
+		if err != nil {
+			var domainErr *Error
+			errors.As(err, &domainErr)
+			row.Status, row.Code, row.Error = SlotImportInvalid, domainErr.Code, domainErr.Message
+			report.Invalid++
+			continue
+		}
+
+		row.Status = SlotImportValid
+		accepted[row.InterviewerID] = append(accepted[row.InterviewerID], *row)
+		report.Valid++
+	}
+
+	if !commit || report.Invalid > 0 || report.Valid == 0 {
+		return report, nil
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for i := range rows {
+			slot := TimeSlot{
+				InterviewerID: rows[i].InterviewerID,
+				StartTime:     rows[i].StartTime,
+				EndTime:       rows[i].EndTime,
+				CreatedAt:     time.Now(),
+				UpdatedAt:     time.Now(),
+			}
+			if err := tx.Create(&slot).Error; err != nil {
+				return fmt.Errorf("failed to create time slot for row %d", rows[i].Row)
+			}
+			rows[i].SlotID = slot.ID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		rows[i].Status = SlotImportCreated
+	}
+	report.Committed = true
+	report.Created = len(rows)
+	return report, nil
+}
+
+// existingSlotsFor loads the stored slots of the import's interviewers within its time span
+func existingSlotsFor(db *gorm.DB, rows []SlotImportRow) (map[uint][]TimeSlot, error) {
+	var interviewerIDs []uint
+	var from, to time.Time
+	seen := map[uint]bool{}
+	for _, row := range rows {
+		if row.Status == SlotImportInvalid {
+			continue
+		}
+		if !seen[row.InterviewerID] {
+			seen[row.InterviewerID] = true
+			interviewerIDs = append(interviewerIDs, row.InterviewerID)
+		}
+		if from.IsZero() || row.StartTime.Before(from) {
+			from = row.StartTime
+		}
+		if row.EndTime.After(to) {
+			to = row.EndTime
+		}
+	}
+
+	existing := map[uint][]TimeSlot{}
+	if len(interviewerIDs) == 0 {
+		return existing, nil
+	}
+	var slots []TimeSlot
+	if err := db.Where("interviewer_id IN ? AND start_time < ? AND end_time > ?", interviewerIDs, to, from).Find(&slots).Error; err != nil {
+		return nil, errors.New("failed to fetch existing time slots")
+	}
+	for _, slot := range slots {
+		existing[slot.InterviewerID] = append(existing[slot.InterviewerID], slot)
+	}
+	return existing, nil
+}
+
+// checkImportOverlap rejects a row that overlaps a stored slot or an earlier row
+// of the same interviewer. Touching intervals do not overlap.
+func checkImportOverlap(existing []TimeSlot, earlier []SlotImportRow, row SlotImportRow) error {
+	for _, other := range earlier {
+		if row.StartTime.Before(other.EndTime) && row.EndTime.After(other.StartTime) {
+			return Conflict("overlaps_row", fmt.Sprintf("overlaps row %d", other.Row))
+		}
+	}
+	for _, slot := range existing {
+		if row.StartTime.Before(slot.EndTime) && row.EndTime.After(slot.StartTime) {
+			return Conflict("overlaps_existing_slot", fmt.Sprintf("overlaps existing time slot %d", slot.ID))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// tenantKey stores the current organization ID in a context.
+type tenantKey struct{}
+
+// WithOrganization returns a context that scopes database access to one organization.
+func WithOrganization(ctx context.Context, organizationID uint) context.Context {
+	return context.WithValue(ctx, tenantKey{}, organizationID)
+}
+
+// OrganizationFromContext returns the organization a context is scoped to.
+func OrganizationFromContext(ctx context.Context) (uint, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	organizationID, ok := ctx.Value(tenantKey{}).(uint)
+	return organizationID, ok && organizationID != 0
+}
+
+// ForOrganization returns a session whose queries only see and write the organization's rows.
+func ForOrganization(db *gorm.DB, organizationID uint) *gorm.DB {
+	return db.WithContext(WithOrganization(db.Statement.Context, organizationID))
+}
+
+// AllOrganizations returns an unscoped session, for lookups by globally unique
+// tokens and for background jobs that serve every tenant.
+func AllOrganizations(db *gorm.DB) *gorm.DB {
+	return db.WithContext(WithOrganization(db.Statement.Context, 0))
+}
+
+// CurrentOrganization returns the organization a session is scoped to, or 0.
+func CurrentOrganization(db *gorm.DB) uint {
+	organizationID, _ := OrganizationFromContext(db.Statement.Context)
+	return organizationID
+}
+
+// RegisterTenantScope adds callbacks that filter every query, update and delete on a
+// model with an OrganizationID field by the session's organization, and stamp it on
+// every created row. Raw SQL is not rewritten and must filter by organization itself.
+func RegisterTenantScope(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("tenant:create", stampOrganization); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", filterOrganization); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", filterOrganization); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("tenant:delete", filterOrganization); err != nil {
+		return err
+	}
+	return callbacks.Row().Before("gorm:row").Register("tenant:row", filterOrganization)
+}
+
+// organizationField returns the statement's OrganizationID field when it should be scoped.
+func organizationField(db *gorm.DB) (*schema.Field, uint) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil, 0
+	}
+	organizationID, ok := OrganizationFromContext(db.Statement.Context)
+	if !ok {
+		return nil, 0
+	}
+	field := db.Statement.Schema.LookUpField("OrganizationID")
+	if field == nil {
+		return nil, 0
+	}
+	return field, organizationID
+}
+
+func filterOrganization(db *gorm.DB) {
+	field, organizationID := organizationField(db)
+	if field == nil || db.Statement.SQL.Len() > 0 {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: organizationID},
+	}})
+}
+
+func stampOrganization(db *gorm.DB) {
+	field, organizationID := organizationField(db)
+	if field == nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+	value := db.Statement.ReflectValue
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if item := reflect.Indirect(value.Index(i)); item.Kind() == reflect.Struct {
+				db.AddError(field.Set(ctx, item, organizationID))
+			}
+		}
+	case reflect.Struct:
+		db.AddError(field.Set(ctx, value, organizationID))
+	case reflect.Map:
+		db.Statement.SetColumn(field.DBName, organizationID)
+	}
+}
@@ -0,0 +1,175 @@
+package pkg
+
+import (
+	"BookingTimeSlot/backend/notifications"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BookingPendingVerification is the status of a booking that holds its slot
+// until the candidate confirms their email address.
+const BookingPendingVerification = "pending_verification"
+
+var (
+	// VerifyBookingEmails makes new candidate bookings wait for email verification.
+	VerifyBookingEmails = false
+	// VerificationHoldDuration is how long an unverified booking holds its slot.
+	VerificationHoldDuration = 30 * time.Minute
+	// VerifyBookingBaseURL prefixes the verification link emailed to candidates.
+	VerifyBookingBaseURL = "http://localhost:3000/verify"
+)
+
+// -------------------- Email Validation --------------------
+
+// ValidateEmail checks an address's syntax without contacting its domain: a
+// bare address such as ann@example.com, with no display name, whose domain
+// has at least two labels and does not end in a number.
+func ValidateEmail(email string) error {
+	invalid := Validation("invalid_email", "email address is not valid")
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Name != "" || address.Address != email {
+		return invalid
+	}
+	at := strings.LastIndex(email, "@")
+	local, domain := email[:at], email[at+1:]
+	if len(local) > 64 || len(domain) > 253 {
+		return invalid
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return invalid
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return invalid
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return invalid
+			}
+		}
+	}
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return invalid
+	}
+	return nil
+}
+
+// -------------------- Verification Functions --------------------
+
+// SendVerificationLink issues a verification token valid for VerificationHoldDuration
+// and emails the link to the candidate. Failures are logged; an unverified
+// booking is released when its hold ends.
+func SendVerificationLink(db *gorm.DB, booking *Booking) {
+	token, err := newToken()
+	if err != nil {
+		slog.ErrorContext(db.Statement.Context, "Failed to issue verification token", "booking_id", booking.ID, "error", err)
+		return
+	}
+
+	expiresAt := time.Now().Add(VerificationHoldDuration)
+	err = db.Model(&Booking{}).Where("id = ?", booking.ID).
+		Updates(map[string]interface{}{"verify_token": hashToken(token), "verify_expires": expiresAt}).Error
+	if err != nil {
+		slog.ErrorContext(db.Statement.Context, "Failed to save verification token", "booking_id", booking.ID, "error", err)
+		return
+	}
+	booking.VerifyExpires = &expiresAt
+
+	notifications.Enqueue(db.Statement.Context, booking.Email, "Confirm your interview booking", fmt.Sprintf(
+		"Hi %s,\n\nPlease confirm your email address to keep your interview on %s: %s?token=%s\n\nUnless confirmed by %s, the slot is released.\n",
+		booking.Name, booking.StartTime.Format(time.RFC1123), VerifyBookingBaseURL, token, expiresAt.Format(time.RFC1123)))
+}
+
+// FindBookingByVerifyToken returns the booking a verification token belongs to.
+func FindBookingByVerifyToken(db *gorm.DB, token string) (*Booking, error) {
+	if token == "" {
+		return nil, NotFound("booking_not_found", "booking not found")
+	}
+
+	var booking Booking
+	if err := db.Preload("Answers").Where("verify_token = ?", hashToken(token)).First(&booking).Error; err != nil {
+		return nil, NotFound("booking_not_found", "booking not found")
+	}
+	return &booking, nil
+}
+
+// VerifyBooking confirms a pending booking once its candidate follows the
+// verification link, then tells the interviewer and sends the manage link.
+func VerifyBooking(db *gorm.DB, booking *Booking) error {
+	result := db.Model(&Booking{}).
+		Where("id = ? AND status = ? AND verify_expires > ?", booking.ID, BookingPendingVerification, time.Now()).
+		Updates(map[string]interface{}{"status": "booked", "verify_token": nil, "updated_at": time.Now()})
+	if result.Error != nil {
+		return errors.New("failed to verify booking")
+	}
+	if result.RowsAffected == 0 {
+		return Gone("verification_link_expired", "verification link has expired")
+	}
+	booking.Status = "booked"
+
+	NotifyInterviewerOfBooking(db, booking.InterviewerID, booking.Name, booking.Email, booking.StartTime, booking.Answers)
+	SendManageLink(db, booking.ID, booking.Name, booking.Email, booking.StartTime, booking.EndTime)
+	return nil
+}
+
+// ReleaseUnverifiedBookings cancels bookings whose verification hold has ended
+// and offers their slots to the waitlist. Given an unscoped session it serves
+// every organization.
+func ReleaseUnverifiedBookings(db *gorm.DB) (int, error) {
+	var expired []Booking
+	if err := db.Where("status = ? AND verify_expires <= ?", BookingPendingVerification, time.Now()).Find(&expired).Error; err != nil {
+		return 0, errors.New("failed to fetch unverified bookings")
+	}
+
+	released := 0
+	for _, booking := range expired {
+		// Each booking is released within its own organization
+		db := ForOrganization(db, booking.OrganizationID)
+		cancelled := false
+		err := db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&Booking{}).Where("id = ? AND status = ?", booking.ID, BookingPendingVerification).
+				Updates(map[string]interface{}{"status": "cancelled", "verify_token": nil, "updated_at": time.Now()})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return nil // verified concurrently
+			}
+			cancelled = true
+			switch booking.SlotKind {
+			case SlotKindTimeSlot:
+				if err := tx.Model(&TimeSlot{}).Where("id = ?", booking.SlotID).Update("is_booked", false).Error; err != nil {
+					return err
+				}
+			case SlotKindAvailability:
+				err := tx.Model(&availabilityWindow{}).Where("id = ?", booking.SlotID).
+					Updates(map[string]interface{}{"booked": false, "booked_by": ""}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return CancelBookingEvents(tx, booking.ID)
+		})
+		if err != nil {
+			return released, errors.New("failed to release unverified booking")
+		}
+		if !cancelled {
+			continue
+		}
+		released++
+
+		if _, err := OfferFreedSlot(db, freedSlotOf(booking)); err != nil {
+			slog.ErrorContext(db.Statement.Context, "Failed to offer unverified booking's slot to the waitlist", "booking_id", booking.ID, "error", err)
+		}
+	}
+	return released, nil
+}
@@ -0,0 +1,57 @@
+package pkg
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// Versioned models, such as TimeSlot and Event, have a Version field counting
+// their changes. A client that read version 3 can ask for its write to apply
+// only if the row is still at version 3, so concurrent edits are refused
+// instead of silently overwriting each other.
+
+// RegisterVersioning adds a callback that increments the Version of every row
+// an update on a versioned model changes. Updates from a map or a column do so
+// in SQL; updates from a struct, e.g. Save, must be of a row read beforehand.
+func RegisterVersioning(db *gorm.DB) error {
+	return db.Callback().Update().Before("gorm:update").Register("version:update", incrementVersion)
+}
+
+func incrementVersion(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	field := db.Statement.Schema.LookUpField("Version")
+	if field == nil {
+		return
+	}
+
+	switch dest := db.Statement.Dest.(type) {
+	case map[string]interface{}:
+		if _, ok := dest[field.DBName]; !ok {
+			dest[field.DBName] = gorm.Expr(field.DBName + " + 1")
+		}
+	default:
+		if db.Statement.ReflectValue.Kind() != reflect.Struct {
+			return
+		}
+		if version, ok := field.ReflectValueOf(db.Statement.Context, db.Statement.ReflectValue).Interface().(uint); ok {
+			db.Statement.SetColumn(field.DBName, version+1)
+		}
+	}
+}
+
+// checkVersion refuses a write expecting ifVersion to a row at version; an
+// ifVersion of 0 expects nothing.
+func checkVersion(version, ifVersion uint, record string) error {
+	if ifVersion != 0 && ifVersion != version {
+		return staleVersion(record)
+	}
+	return nil
+}
+
+// staleVersion is the error for a write to a record changed since it was read
+func staleVersion(record string) error {
+	return PreconditionFailed("version_mismatch", record+" was changed by someone else; reload it and try again")
+}
@@ -0,0 +1,424 @@
+package pkg
+
+import (
+	"BookingTimeSlot/backend/notifications"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Freed slot kinds, naming the model a waitlist offer points back to
+const (
+	SlotKindAvailability = "availability" // handler.Availability window
+	SlotKindTimeSlot     = "time_slot"    // TimeSlot row
+	SlotKindSchedule     = "schedule"     // interval generated from a ScheduleTemplate
+)
+
+// Waitlist entry and offer statuses
+const (
+	WaitlistWaiting   = "waiting"
+	WaitlistOffered   = "offered"
+	WaitlistBooked    = "booked"
+	WaitlistCancelled = "cancelled"
+
+	OfferPending = "pending"
+	OfferClaimed = "claimed"
+	OfferExpired = "expired"
+)
+
+var (
+	// WaitlistHoldDuration is how long a freed slot is reserved for the offered candidate.
+	WaitlistHoldDuration = 30 * time.Minute
+	// WaitlistClaimBaseURL prefixes the claim link sent to candidates.
+	WaitlistClaimBaseURL = "http://localhost:3000/waitlist/claim"
+)
+
+// -------------------- Models --------------------
+
+// WaitlistEntry is a candidate waiting for an opening. Zero or empty filters match anything.
+type WaitlistEntry struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"index"`
+	Name           string    `json:"name"`
+	Email          string    `json:"email"`
+	InterviewerID  uint      `json:"interviewer_id,omitempty" gorm:"index"`
+	EventTypeID    uint      `json:"event_type_id,omitempty"`
+	FromDate       string    `json:"from_date,omitempty"` // YYYY-MM-DD, inclusive
+	ToDate         string    `json:"to_date,omitempty"`   // YYYY-MM-DD, inclusive
+	Status         string    `json:"status" gorm:"index"`
+	LeaveToken     *string   `json:"-" gorm:"uniqueIndex"` // SHA-256 of the token that lets the candidate leave
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// WaitlistOffer holds a freed slot for one waitlisted candidate until it is claimed or expires.
+type WaitlistOffer struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"index"`
+	EntryID        uint      `json:"entry_id" gorm:"index"`
+	SlotKind       string    `json:"slot_kind"`
+	SlotID         uint      `json:"slot_id,omitempty"`
+	InterviewerID  uint      `json:"interviewer_id" gorm:"index"`
+	EventTypeID    uint      `json:"event_type_id,omitempty"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	Token          string    `json:"-" gorm:"uniqueIndex"` // SHA-256 of the token that claims the offer
+	ExpiresAt      time.Time `json:"expires_at" gorm:"index"`
+	Status         string    `json:"status" gorm:"index"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// FreedSlot describes a slot released by a cancellation.
+type FreedSlot struct {
+	Kind          string
+	SlotID        uint
+	InterviewerID uint
+	EventTypeID   uint
+	StartTime     time.Time
+	EndTime       time.Time
+}
+
+// -------------------- Waitlist Functions --------------------
+
+// JoinWaitlist adds a candidate to the waitlist. The returned token lets the
+// candidate leave again; only its hash is stored.
+func JoinWaitlist(db *gorm.DB, name, email string, interviewerID, eventTypeID uint, fromDate, toDate string) (*WaitlistEntry, string, error) {
+	if name == "" || email == "" {
+		return nil, "", Validation("name_and_email_required", "name and email are required")
+	}
+	for _, date := range []string{fromDate, toDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, "", Validation("invalid_date", "invalid date format; expected YYYY-MM-DD")
+		}
+	}
+	if fromDate != "" && toDate != "" && toDate < fromDate {
+		return nil, "", Validation("invalid_date_range", "to date must not be before from date")
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, "", err
+	}
+	hash := hashToken(token)
+
+	entry := WaitlistEntry{
+		Name:          name,
+		Email:         email,
+		InterviewerID: interviewerID,
+		EventTypeID:   eventTypeID,
+		FromDate:      fromDate,
+		ToDate:        toDate,
+		Status:        WaitlistWaiting,
+		LeaveToken:    &hash,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if err := db.Create(&entry).Error; err != nil {
+		return nil, "", errors.New("failed to join waitlist")
+	}
+	return &entry, token, nil
+}
+
+// CheckLeaveToken verifies that token is the leave token of entry id.
+func CheckLeaveToken(db *gorm.DB, id uint, token string) error {
+	var count int64
+	if token != "" {
+		if err := db.Model(&WaitlistEntry{}).Where("id = ? AND leave_token = ?", id, hashToken(token)).Count(&count).Error; err != nil {
+			return errors.New("failed to fetch waitlist entry")
+		}
+	}
+	if count == 0 {
+		return NotFound("waitlist_entry_not_found", "waitlist entry not found")
+	}
+	return nil
+}
+
+// LeaveWaitlist cancels a waiting entry. A slot the entry was being offered is
+// released and offered to the next candidate in line.
+func LeaveWaitlist(db *gorm.DB, id uint) error {
+	var released []WaitlistOffer
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&WaitlistEntry{}).
+			Where("id = ? AND status IN ?", id, []string{WaitlistWaiting, WaitlistOffered}).
+			Updates(map[string]interface{}{"status": WaitlistCancelled, "updated_at": time.Now()})
+		if result.Error != nil {
+			return errors.New("failed to leave waitlist")
+		}
+		if result.RowsAffected == 0 {
+			return NotFound("waitlist_entry_not_found", "waitlist entry not found")
+		}
+
+		if err := tx.Where("entry_id = ? AND status = ?", id, OfferPending).Find(&released).Error; err != nil {
+			return errors.New("failed to fetch waitlist offers")
+		}
+		if len(released) == 0 {
+			return nil
+		}
+		if err := tx.Model(&WaitlistOffer{}).Where("entry_id = ? AND status = ?", id, OfferPending).
+			Updates(map[string]interface{}{"status": OfferExpired, "updated_at": time.Now()}).Error; err != nil {
+			return errors.New("failed to expire waitlist offers")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, offer := range released {
+		if _, err := OfferFreedSlot(db, freedSlotOfOffer(offer)); err != nil {
+			slog.ErrorContext(db.Statement.Context, "Failed to re-offer slot from waitlist offer", "offer_id", offer.ID, "interviewer_id", offer.InterviewerID, "error", err)
+		}
+	}
+	return nil
+}
+
+// OfferFreedSlot reserves a freed slot for the longest-waiting matching candidate and
+// emails them a claim link. It returns nil when nobody on the waitlist matches or
+// the slot has already started.
+func OfferFreedSlot(db *gorm.DB, freed FreedSlot) (*WaitlistOffer, error) {
+	if !freed.StartTime.After(time.Now()) {
+		return nil, nil
+	}
+	date := freed.StartTime.UTC().Format("2006-01-02")
+
+	// Candidates already offered this exact slot are skipped so an expired offer moves on
+	alreadyOffered := db.Model(&WaitlistOffer{}).Select("entry_id").
+		Where("interviewer_id = ? AND start_time = ?", freed.InterviewerID, freed.StartTime.UTC())
+
+	var entry WaitlistEntry
+	err := db.Where("status = ?", WaitlistWaiting).
+		Where("interviewer_id = 0 OR interviewer_id = ?", freed.InterviewerID).
+		Where("event_type_id = 0 OR ? = 0 OR event_type_id = ?", freed.EventTypeID, freed.EventTypeID).
+		Where("from_date = '' OR from_date <= ?", date).
+		Where("to_date = '' OR to_date >= ?", date).
+		Where("id NOT IN (?)", alreadyOffered).
+		Order("created_at, id").
+		First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to search waitlist")
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	offer := WaitlistOffer{
+		EntryID:       entry.ID,
+		SlotKind:      freed.Kind,
+		SlotID:        freed.SlotID,
+		InterviewerID: freed.InterviewerID,
+		EventTypeID:   freed.EventTypeID,
+		StartTime:     freed.StartTime.UTC(),
+		EndTime:       freed.EndTime.UTC(),
+		Token:         hashToken(token),
+		ExpiresAt:     time.Now().Add(WaitlistHoldDuration),
+		Status:        OfferPending,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&offer).Error; err != nil {
+			return errors.New("failed to create waitlist offer")
+		}
+		if err := tx.Model(&entry).Updates(map[string]interface{}{"status": WaitlistOffered, "updated_at": time.Now()}).Error; err != nil {
+			return errors.New("failed to update waitlist entry")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	notifications.Enqueue(db.Statement.Context, entry.Email, "An interview slot opened up", fmt.Sprintf(
+		"Hi %s,\n\nA slot on %s is available and reserved for you until %s.\nClaim it here: %s?token=%s\n",
+		entry.Name, offer.StartTime.Format(time.RFC1123), offer.ExpiresAt.Format(time.RFC1123), WaitlistClaimBaseURL, token))
+
+	return &offer, nil
+}
+
+// ExpireWaitlistOffers closes offers past their hold time, returns their candidates to
+// the waitlist and offers each slot to the next candidate in line. Given an unscoped
+// session it serves every organization.
+func ExpireWaitlistOffers(db *gorm.DB) (int, error) {
+	var expired []WaitlistOffer
+	if err := db.Where("status = ? AND expires_at <= ?", OfferPending, time.Now()).Find(&expired).Error; err != nil {
+		return 0, errors.New("failed to fetch expired waitlist offers")
+	}
+
+	for _, offer := range expired {
+		// Each offer is handled within its own organization
+		db := ForOrganization(db, offer.OrganizationID)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&WaitlistOffer{}).Where("id = ? AND status = ?", offer.ID, OfferPending).
+				Updates(map[string]interface{}{"status": OfferExpired, "updated_at": time.Now()})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return nil // claimed concurrently
+			}
+			return tx.Model(&WaitlistEntry{}).Where("id = ? AND status = ?", offer.EntryID, WaitlistOffered).
+				Updates(map[string]interface{}{"status": WaitlistWaiting, "updated_at": time.Now()}).Error
+		})
+		if err != nil {
+			return 0, errors.New("failed to expire waitlist offer")
+		}
+
+		if _, err = OfferFreedSlot(db, freedSlotOfOffer(offer)); err != nil {
+			slog.ErrorContext(db.Statement.Context, "Failed to re-offer slot from waitlist offer", "offer_id", offer.ID, "interviewer_id", offer.InterviewerID, "error", err)
+		}
+	}
+
+	return len(expired), nil
+}
+
+// freedSlotOfOffer describes the slot an offer that is not claimed gives back
+func freedSlotOfOffer(offer WaitlistOffer) FreedSlot {
+	return FreedSlot{
+		Kind:          offer.SlotKind,
+		SlotID:        offer.SlotID,
+		InterviewerID: offer.InterviewerID,
+		EventTypeID:   offer.EventTypeID,
+		StartTime:     offer.StartTime,
+		EndTime:       offer.EndTime,
+	}
+}
+
+// FindPendingOffer returns an unexpired, unclaimed offer and its waitlist entry by token.
+func FindPendingOffer(db *gorm.DB, token string) (*WaitlistOffer, *WaitlistEntry, error) {
+	var offer WaitlistOffer
+	if err := db.Where("token = ?", hashToken(token)).First(&offer).Error; err != nil {
+		return nil, nil, NotFound("waitlist_offer_not_found", "waitlist offer not found")
+	}
+	if offer.Status != OfferPending || !offer.ExpiresAt.After(time.Now()) {
+		return nil, nil, Gone("waitlist_offer_unavailable", "waitlist offer is no longer available")
+	}
+
+	var entry WaitlistEntry
+	if err := db.First(&entry, offer.EntryID).Error; err != nil {
+		return nil, nil, NotFound("waitlist_entry_not_found", "waitlist entry not found")
+	}
+	return &offer, &entry, nil
+}
+
+// BookOfferedSlot claims an offer and books its slot for the candidate through
+// PlaceBooking. The handler that owns availability windows marks them booked
+// in reserve, which runs in the same transaction.
+func BookOfferedSlot(db *gorm.DB, offer *WaitlistOffer, entry *WaitlistEntry, reserve func(tx *gorm.DB) error) (*Booking, error) {
+	booking := Booking{
+		Name:          entry.Name,
+		Email:         entry.Email,
+		SlotID:        offer.SlotID,
+		SlotKind:      offer.SlotKind,
+		InterviewerID: offer.InterviewerID,
+		EventTypeID:   offer.EventTypeID,
+		StartTime:     offer.StartTime,
+		EndTime:       offer.EndTime,
+	}
+	err := PlaceBooking(db, &booking, func(tx *gorm.DB) error {
+		if err := CompleteWaitlistOffer(tx, offer); err != nil {
+			return err
+		}
+		if offer.SlotKind == SlotKindTimeSlot {
+			if err := reserveTimeSlot(tx, offer.SlotID); err != nil {
+				return err
+			}
+		}
+		if reserve != nil {
+			return reserve(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// CompleteWaitlistOffer marks an offer claimed and its entry booked.
+func CompleteWaitlistOffer(tx *gorm.DB, offer *WaitlistOffer) error {
+	result := tx.Model(&WaitlistOffer{}).Where("id = ? AND status = ?", offer.ID, OfferPending).
+		Updates(map[string]interface{}{"status": OfferClaimed, "updated_at": time.Now()})
+	if result.Error != nil {
+		return errors.New("failed to claim waitlist offer")
+	}
+	if result.RowsAffected == 0 {
+		return Gone("waitlist_offer_unavailable", "waitlist offer is no longer available")
+	}
+
+	if err := tx.Model(&WaitlistEntry{}).Where("id = ?", offer.EntryID).
+		Updates(map[string]interface{}{"status": WaitlistBooked, "updated_at": time.Now()}).Error; err != nil {
+		return errors.New("failed to update waitlist entry")
+	}
+	return nil
+}
+
+// ActiveHolds returns pending offers for the interviewers, or for all when
+// empty, that overlap [start, end).
+func ActiveHolds(db *gorm.DB, interviewerIDs []uint, start, end time.Time) ([]WaitlistOffer, error) {
+	query := db.Where("status = ? AND expires_at > ? AND start_time < ? AND end_time > ?", OfferPending, time.Now(), end.UTC(), start.UTC())
+	if len(interviewerIDs) > 0 {
+		query = query.Where("interviewer_id IN ?", interviewerIDs)
+	}
+	var offers []WaitlistOffer
+	if err := query.Find(&offers).Error; err != nil {
+		return nil, errors.New("failed to fetch waitlist holds")
+	}
+	return offers, nil
+}
+
+// HeldSlotIDs returns the IDs of slots of a kind that are currently held for waitlisted candidates.
+func HeldSlotIDs(db *gorm.DB, kind string) (map[uint]bool, error) {
+	var ids []uint
+	err := db.Model(&WaitlistOffer{}).
+		Where("slot_kind = ? AND status = ? AND expires_at > ?", kind, OfferPending, time.Now()).
+		Pluck("slot_id", &ids).Error
+	if err != nil {
+		return nil, errors.New("failed to fetch waitlist holds")
+	}
+
+	held := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		held[id] = true
+	}
+	return held, nil
+}
+
+// migrateOfferTokens replaces the tokens offers kept in plain text, before
+// they were stored hashed, with their SHA-256, so emailed links keep working.
+func migrateOfferTokens(db *gorm.DB) error {
+	var offers []WaitlistOffer
+	if err := db.Select("id, token").Where("LENGTH(token) <> ?", len(hashToken(""))).Find(&offers).Error; err != nil {
+		return fmt.Errorf("failed to fetch waitlist offers with plain tokens: %w", err)
+	}
+	for _, offer := range offers {
+		if err := db.Model(&WaitlistOffer{}).Where("id = ?", offer.ID).Update("token", hashToken(offer.Token)).Error; err != nil {
+			return fmt.Errorf("failed to hash the token of waitlist offer %d: %w", offer.ID, err)
+		}
+	}
+	return nil
+}
+
+// newToken returns a random URL-safe token.
+func newToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.New("failed to generate token")
+	}
+	return hex.EncodeToString(buf), nil
+}
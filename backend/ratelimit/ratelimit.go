@@ -0,0 +1,127 @@
+// Package ratelimit limits how often a key, such as a client IP or an email
+// address, may do something, using token buckets kept in a pluggable Store.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Burst requests at once, refilled at Rate per second.
+// A zero Limit allows everything.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerHour allows n requests an hour, up to burst of them at once
+func PerHour(n, burst int) Limit {
+	if n <= 0 {
+		return Limit{}
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return Limit{Rate: float64(n) / time.Hour.Seconds(), Burst: burst}
+}
+
+// Unlimited reports whether the limit allows everything
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0
+}
+
+// Store keeps the buckets. Take must be atomic per key, so a store shared by
+// several server processes, e.g. in Redis, enforces one limit across them.
+type Store interface {
+	// Take removes a token from key's bucket. When the bucket is empty it
+	// returns false and how long until the next token.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (bool, time.Duration, error)
+}
+
+// Limiter applies one Limit to many keys
+type Limiter struct {
+	Name  string // e.g. "ip", used in keys, logs and metrics
+	limit Limit
+	store Store
+}
+
+// New returns a limiter keeping its buckets in store
+func New(name string, limit Limit, store Store) *Limiter {
+	return &Limiter{Name: name, limit: limit, store: store}
+}
+
+// Allow takes a token for key. When none is left it returns false and how long
+// the caller should wait before retrying. A nil Limiter allows everything.
+func (l *Limiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	if l == nil || l.limit.Unlimited() || key == "" {
+		return true, 0, nil
+	}
+	return l.store.Take(ctx, l.Name+":"+key, l.limit, time.Now())
+}
+
+// -------------------- Memory Store --------------------
+
+// sweepInterval is how often MemoryStore drops buckets that have refilled
+const sweepInterval = time.Minute
+
+// bucket is the tokens a key had at updated
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// refill returns the bucket's tokens at now
+func (b *bucket) refill(now time.Time) float64 {
+	elapsed := now.Sub(b.updated).Seconds()
+	return math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+}
+
+// MemoryStore keeps buckets in process memory, so each server process limits
+// on its own. Full buckets are dropped, bounding memory by the active keys.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore returns an empty in-process store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+	b.tokens, b.updated = b.refill(now), now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+		return false, wait, nil
+	}
+	b.tokens--
+	return true, 0, nil
+}
+
+// sweep drops buckets that have refilled, as they are the same as no bucket
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if b.refill(now) >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -1,93 +1,201 @@
 package routes
 
 import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/handler"
 	"BookingTimeSlot/backend/pkg"
 
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-// SetUpRoutes initializes the routes for booking and availability
+// BookingRequest books a stored time slot by slot_id, or an interval of a
+// weekly schedule by interviewer_id, start_time and end_time, with intake
+// answers keyed by question key
+type BookingRequest struct {
+	SlotID        uint              `json:"slot_id,omitempty"`
+	InterviewerID uint              `json:"interviewer_id,omitempty"`
+	StartTime     time.Time         `json:"start_time,omitempty"`
+	EndTime       time.Time         `json:"end_time,omitempty"`
+	EventTypeID   uint              `json:"event_type_id,omitempty"`
+	Name          string            `json:"name"`
+	Email         string            `json:"email"`
+	Answers       map[string]string `json:"answers"`
+}
+
+// SetUpRoutes initializes the legacy unversioned routes for booking and availability.
+// They are kept as adapters of their /api/v1 successors.
 func SetUpRoutes(router *gin.Engine) {
-	// POST endpoint: Create a booking
-	router.POST("/bookings", func(c *gin.Context) {
-		var booking pkg.Booking
+	router.POST("/bookings", handler.Deprecated("/api/v1/bookings"), handler.CountBookingAttempts(), handler.ProtectBooking(), CreateBooking)
+	router.GET("/bookings", handler.Deprecated("/api/v1/bookings"), auth.RequireRole(auth.RoleInterviewer, auth.RoleAdmin), GetBookings)
+	router.GET("/availability", handler.Deprecated("/api/v1/availability"), GetAvailableSlots)
+}
 
-		// Bind incoming JSON to booking model
-		if err := c.ShouldBindJSON(&booking); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
-			return
-		}
+// CreateBooking books a stored time slot by slot_id, or an interval of an
+// interviewer's weekly schedule by interviewer_id, start_time and end_time
+func CreateBooking(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB) // scoped to the request's organization
+	var req BookingRequest
 
-		// Check if slot already booked for given date
-		var existingBooking pkg.Booking
-		if err := pkg.DB.Where("slot = ? AND date = ?", booking.Slot, booking.Date).First(&existingBooking).Error; err == nil {
-			c.JSON(http.StatusConflict, gin.H{"error": "Slot already booked"})
-			return
-		}
+	// Bind incoming JSON to the booking request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
 
-		// Save the booking
-		if err := pkg.DB.Create(&booking).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking"})
-			return
-		}
+	if req.SlotID != 0 {
+		createTimeSlotBooking(c, db, req)
+		return
+	}
+
+	booking := pkg.Booking{
+		Name:          req.Name,
+		Email:         strings.TrimSpace(req.Email),
+		InterviewerID: req.InterviewerID,
+		EventTypeID:   req.EventTypeID,
+		StartTime:     req.StartTime,
+		EndTime:       req.EndTime,
+	}
+	if err := pkg.ValidateEmail(booking.Email); err != nil {
+		c.Error(err)
+		return
+	}
+	if booking.InterviewerID == 0 || booking.StartTime.IsZero() || booking.EndTime.IsZero() {
+		c.Error(pkg.Validation("interviewer_and_times_required", "Interviewer, start time and end time are required"))
+		return
+	}
+	if !booking.StartTime.Before(booking.EndTime) {
+		c.Error(pkg.Validation("invalid_time_range", "Start time must be before end time"))
+		return
+	}
+	db = handler.LogFields(c, db, "interviewer_id", booking.InterviewerID)
+	if err := handler.LimitBooking(c, booking.Email, booking.InterviewerID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Validate custom booking form fields for the event type
+	answers, err := pkg.ValidateAnswers(db, booking.EventTypeID, req.Answers)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-		// Success response
-		c.JSON(http.StatusOK, gin.H{
-			"message": "Booking successfully created",
-			"booking": booking,
-		})
+	booking.Answers = answers
+
+	// The interval must still be offered when the booking is saved
+	if err := pkg.BookScheduleSlot(db, &booking); err != nil {
+		c.Error(err)
+		return
+	}
+	handler.LogFields(c, db, "booking_id", booking.ID)
+	slog.InfoContext(c.Request.Context(), "Booking created")
+
+	// Success response
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Booking successfully created",
+		"booking": booking,
 	})
+}
 
-	// GET endpoint: Retrieve all bookings
-	router.GET("/bookings", func(c *gin.Context) {
-		var bookings []pkg.Booking
-		if err := pkg.DB.Find(&bookings).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bookings"})
-			return
-		}
+// createTimeSlotBooking books a stored time slot and saves the intake answers given with it
+func createTimeSlotBooking(c *gin.Context, db *gorm.DB, req BookingRequest) {
+	db = handler.LogFields(c, db, "slot_id", req.SlotID)
+
+	// The per-interviewer limit needs the slot's interviewer before booking it
+	var slot pkg.TimeSlot
+	if err := db.Select("interviewer_id").First(&slot, req.SlotID).Error; err != nil {
+		c.Error(pkg.NotFound("slot_not_found", "time slot not found"))
+		return
+	}
+	if err := handler.LimitBooking(c, req.Email, slot.InterviewerID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	answers, err := pkg.ValidateAnswers(db, req.EventTypeID, req.Answers)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-		c.JSON(http.StatusOK, gin.H{"bookings": bookings})
+	booking := pkg.Booking{
+		Name:        req.Name,
+		Email:       req.Email,
+		SlotID:      req.SlotID,
+		EventTypeID: req.EventTypeID,
+		Answers:     answers,
+	}
+	if err := pkg.CreateBooking(db, &booking); err != nil {
+		c.Error(err)
+		return
+	}
+	handler.LogFields(c, db, "booking_id", booking.ID, "interviewer_id", booking.InterviewerID)
+	slog.InfoContext(c.Request.Context(), "Booking created")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Booking successfully created",
+		"booking": booking,
 	})
+}
 
-	// GET endpoint: Retrieve available slots for a specific date
-	router.GET("/availability", func(c *gin.Context) {
-		date := c.Query("date")
-		if date == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Date is required"})
-			return
-		}
+// GetBookings lists bookings with their intake answers; interviewers only see their own
+func GetBookings(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	query := db.Preload("Answers")
+	if claims := auth.ClaimsFrom(c); claims != nil && claims.Role == auth.RoleInterviewer {
+		query = query.Where("interviewer_id = ?", claims.InterviewerID)
+	}
+
+	var bookings []pkg.Booking
+	if err := query.Find(&bookings).Error; err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bookings": bookings})
+}
+
+// GetAvailableSlots lists open slots generated from weekly schedules for a date
+func GetAvailableSlots(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	date := c.Query("date")
+	if date == "" {
+		c.Error(pkg.Validation("date_required", "Date is required"))
+		return
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		c.Error(pkg.Validation("invalid_date", "Invalid date format. Use YYYY-MM-DD."))
+		return
+	}
 
-		// Retrieve bookings for the given date
-		var bookings []pkg.Booking
-		if err := pkg.DB.Where("date = ?", date).Find(&bookings).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bookings"})
+	// Optional interviewer filter; without it every interviewer's schedule is used
+	var interviewerID uint
+	if param := c.Query("interviewer_id"); param != "" {
+		id, err := strconv.Atoi(param)
+		if err != nil || id <= 0 {
+			c.Error(pkg.Validation("invalid_interviewer_id", "Invalid interviewer ID"))
 			return
 		}
+		interviewerID = uint(id)
+	}
 
-		// All available slots
-		allSlots := []string{"9:00 AM", "11:00 AM", "2:00 PM", "4:00 PM"}
-		availableSlots := []string{}
-
-		// Filter out booked slots
-		for _, slot := range allSlots {
-			isBooked := false
-			for _, booking := range bookings {
-				if booking.Slot == slot {
-					isBooked = true
-					break
-				}
-			}
-			if !isBooked {
-				availableSlots = append(availableSlots, slot)
-			}
-		}
+	// Generate slots from stored schedule templates, minus overlapping bookings
+	availableSlots, err := pkg.GenerateAvailableSlots(db, interviewerID, date)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-		c.JSON(http.StatusOK, gin.H{
-			"date":            date,
-			"available_slots": availableSlots,
-			"total_available": len(availableSlots),
-		})
+	c.JSON(http.StatusOK, gin.H{
+		"date":            date,
+		"available_slots": availableSlots,
+		"total_available": len(availableSlots),
 	})
 }
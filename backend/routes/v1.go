@@ -0,0 +1,83 @@
+package routes
+
+import (
+	"BookingTimeSlot/backend/auth"
+	"BookingTimeSlot/backend/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetUpV1Routes registers the versioned API. Resources are plural nouns, and
+// anything owned by an interviewer is nested under /interviewers/:interviewer_id.
+func SetUpV1Routes(router *gin.Engine) {
+	staff := auth.RequireRole(auth.RoleInterviewer, auth.RoleAdmin)
+	admin := auth.RequireRole(auth.RoleAdmin)
+
+	v1 := router.Group("/api/v1")
+	{
+		// Interviewers and what they offer
+		v1.GET("/interviewers", handler.GetInterviewers)
+		v1.POST("/interviewers", staff, handler.CreateInterviewer)
+		v1.GET("/interviewers/:interviewer_id/availability", handler.GetInterviewerAvailability)
+		v1.GET("/interviewers/:interviewer_id/overrides", handler.GetAvailabilityOverrides)
+		v1.POST("/interviewers/:interviewer_id/overrides", staff, handler.SetAvailabilityOverride)
+		v1.DELETE("/overrides/:id", staff, handler.DeleteAvailabilityOverride)
+		v1.GET("/interviewers/:interviewer_id/schedules", handler.GetScheduleTemplates)
+		v1.POST("/interviewers/:interviewer_id/schedules", staff, handler.CreateScheduleTemplate)
+		v1.DELETE("/schedules/:id", staff, handler.DeleteScheduleTemplate)
+		v1.GET("/holidays", handler.GetHolidays)
+		v1.POST("/holidays", admin, handler.UploadHolidays)
+
+		// Open slots
+		v1.GET("/availability", GetAvailableSlots)
+		v1.POST("/availability", staff, handler.SetInterviewerAvailability)
+		v1.GET("/availability/:id", handler.GetAvailabilityWindow)
+		v1.PATCH("/availability/:id", staff, handler.UpdateInterviewerAvailability)
+		v1.GET("/availability/range", handler.GetAvailabilityRange)
+		v1.GET("/slots", handler.GetTimeSlots)
+		v1.POST("/slots", staff, handler.CreateTimeSlot)
+		v1.POST("/slots/import", staff, handler.ImportTimeSlots)
+		v1.GET("/slots/:id", handler.GetTimeSlot)
+		v1.PATCH("/slots/:id", staff, handler.UpdateTimeSlot)
+
+		// Bookings and the events they create
+		v1.GET("/bookings", staff, GetBookings)
+		v1.POST("/bookings", handler.CountBookingAttempts(), handler.ProtectBooking(), CreateBooking)
+		v1.GET("/bookings/export", staff, handler.ExportBookings)
+		v1.GET("/bookings/:id", staff, handler.GetBooking)
+		v1.DELETE("/bookings/:id", staff, handler.CancelBooking)
+		v1.GET("/events", staff, handler.GetEvents)
+		v1.POST("/events", staff, handler.CreateEvent)
+		v1.GET("/events/:id", staff, handler.GetEvent)
+		v1.PATCH("/events/:id", staff, handler.UpdateEvent)
+		v1.DELETE("/events/:id", staff, handler.DeleteEvent)
+
+		// Reporting
+		v1.GET("/analytics", staff, handler.GetSchedulingAnalytics)
+
+		// Self-service links
+		v1.GET("/manage/:token", handler.GetManagedBooking)
+		v1.POST("/manage/:token/cancel", handler.CancelManagedBooking)
+		v1.POST("/manage/:token/reschedule", handler.RescheduleManagedBooking)
+		v1.POST("/bookings/verify/:token", handler.VerifyBooking)
+		v1.POST("/waitlist", handler.JoinWaitlist)
+		v1.DELETE("/waitlist/:id", handler.LeaveWaitlist)
+		v1.GET("/waitlist/offers/:token", handler.GetWaitlistOffer)
+		v1.POST("/waitlist/offers/:token/claim", handler.CountBookingAttempts(), handler.ProtectBooking(), handler.ClaimWaitlistOffer)
+
+		// Event types and intake questions
+		v1.GET("/event-types", handler.GetEventTypes)
+		v1.POST("/event-types", staff, handler.CreateEventType)
+		v1.GET("/event-types/:id/questions", handler.GetIntakeQuestions)
+		v1.POST("/event-types/:id/questions", staff, handler.CreateIntakeQuestion)
+		v1.DELETE("/questions/:id", staff, handler.DeleteIntakeQuestion)
+
+		// Administration
+		v1.GET("/organizations", admin, handler.GetOrganizations)
+		v1.POST("/organizations", admin, handler.CreateOrganization)
+		v1.GET("/admin/audit-logs", admin, handler.GetAuditLogs)
+		v1.GET("/admin/legacy-usage", admin, handler.GetLegacyUsage)
+		v1.GET("/admin/overlaps", admin, handler.GetOverlapConflicts)
+		v1.POST("/admin/analytics/rollups", admin, handler.RefreshAnalyticsRollups)
+	}
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// querySpanKey stores a statement's querySpan between its callbacks
+const querySpanKey = "tracing:span"
+
+// querySpan is a statement's span and the operation it was started for
+type querySpan struct {
+	span      trace.Span
+	operation string
+}
+
+// RegisterQueryTracing adds callbacks that record a client span around every
+// create, query, update, delete, row and raw statement. Queries are only traced
+// when the session's context already has a span, such as a request's, so
+// background jobs don't each start a trace per query.
+func RegisterQueryTracing(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("*").Register("tracing:start_create", startQuerySpan("INSERT")); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("*").Register("tracing:end_create", endQuerySpan); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("*").Register("tracing:start_query", startQuerySpan("SELECT")); err != nil {
+		return err
+	}
+	if err := callbacks.Query().After("*").Register("tracing:end_query", endQuerySpan); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("*").Register("tracing:start_update", startQuerySpan("UPDATE")); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("*").Register("tracing:end_update", endQuerySpan); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("*").Register("tracing:start_delete", startQuerySpan("DELETE")); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("*").Register("tracing:end_delete", endQuerySpan); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("*").Register("tracing:start_row", startQuerySpan("ROW")); err != nil {
+		return err
+	}
+	if err := callbacks.Row().After("*").Register("tracing:end_row", endQuerySpan); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().Before("*").Register("tracing:start_raw", startQuerySpan("RAW")); err != nil {
+		return err
+	}
+	return callbacks.Raw().After("*").Register("tracing:end_raw", endQuerySpan)
+}
+
+func startQuerySpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+		_, span := Tracer().Start(ctx, operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(databaseSystem(db), semconv.DBOperationName(operation)),
+		)
+		db.InstanceSet(querySpanKey, querySpan{span: span, operation: operation})
+	}
+}
+
+// endQuerySpan names the span after the statement's table and records its SQL,
+// with placeholders rather than values, and any error but not found
+func endQuerySpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(querySpanKey)
+	if !ok {
+		return
+	}
+	current := value.(querySpan)
+	span := current.span
+	defer span.End()
+
+	if table := db.Statement.Table; table != "" {
+		span.SetName(current.operation + " " + table)
+		span.SetAttributes(semconv.DBCollectionName(table))
+	}
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
+
+// databaseSystem reports the dialect in OpenTelemetry's naming
+func databaseSystem(db *gorm.DB) attribute.KeyValue {
+	if name := db.Dialector.Name(); name != "postgres" {
+		return semconv.DBSystemKey.String(name)
+	}
+	return semconv.DBSystemPostgreSQL
+}
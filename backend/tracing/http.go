@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"BookingTimeSlot/backend/logging"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware records a server span per request, continuing the trace of an
+// incoming traceparent header. The span is put in the request context, and its
+// trace ID is attached to the request's log lines. Register it after
+// RequestID so the span carries the request ID.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		name := c.Request.Method
+		if route := c.FullPath(); route != "" {
+			name += " " + route
+		}
+		ctx, span := Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(c.FullPath()),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+				semconv.UserAgentOriginal(c.Request.UserAgent()),
+				attribute.String("request.id", c.GetString("request_id")),
+			),
+		)
+		defer span.End()
+
+		if spanContext := span.SpanContext(); spanContext.IsValid() {
+			ctx = logging.With(ctx, "trace_id", spanContext.TraceID().String())
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+			if err := c.Errors.Last(); err != nil {
+				span.RecordError(err.Err)
+			}
+		}
+	}
+}